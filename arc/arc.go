@@ -0,0 +1,354 @@
+// Package arc 实现了自适应替换缓存（Adaptive Replacement Cache）
+//
+// ARC同时维护两个常驻链表：T1保存只被访问过一次的元素（近期性），
+// T2保存被访问过至少两次的元素（频率）；以及两个只保存键的幽灵链表：
+// B1和B2分别记录最近从T1、T2中淘汰的键。当幽灵链表被命中时，
+// 目标大小p会向对应一侧调整，使缓存在近期性和频率之间自适应。
+// 由于只访问一次的扫描流量只会进入T1，ARC不会像LRU那样被扫描冲刷。
+//
+// 所有链表的头部是最久未访问的一端，尾部是最近访问的一端。
+package arc
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"iter"
+)
+
+// 元素所在的链表
+const (
+	inT1 = iota
+	inT2
+	inB1
+	inB2
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	where int
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[entry[K, V]]
+
+type ARC[K comparable, V any] struct {
+	items    map[K]*node[K, V]
+	t1, t2   *double_linked_list.DoubleLinkedList[entry[K, V]]
+	b1, b2   *double_linked_list.DoubleLinkedList[entry[K, V]]
+	p        int // T1的目标大小
+	capacity int
+	onEvict  func(key K, value V, reason cache.EvictReason)
+	stats    cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*ARC[string, int])(nil)
+
+// New 根据构造选项创建ARC缓存，容量通过 cache.WithCapacity 指定
+func New[K comparable, V any](opts ...cache.Option) *ARC[K, V] {
+	o := cache.NewOptions(opts...)
+
+	return &ARC[K, V]{
+		items:    make(map[K]*node[K, V]),
+		t1:       double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		t2:       double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		b1:       double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		b2:       double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		capacity: o.Capacity,
+		onEvict:  cache.OnEvict[K, V](o),
+	}
+}
+
+// NewARC 创建容量为capacity的ARC缓存
+func NewARC[K comparable, V any](capacity int, opts ...cache.Option) *ARC[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// Get 返回key对应的值，命中的元素被移动到T2的尾部
+func (a *ARC[K, V]) Get(key K) (V, bool) {
+	var zero V
+	n, ok := a.resident(key)
+	if !ok {
+		a.stats.Miss()
+		return zero, false
+	}
+
+	a.stats.Hit()
+	n = a.move(n, a.t2, inT2)
+	return n.Val.value, true
+}
+
+// Peek 返回key对应的值，不影响淘汰顺序
+func (a *ARC[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	n, ok := a.resident(key)
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否在缓存中，幽灵链表中的键不算在内
+func (a *ARC[K, V]) Contains(key K) bool {
+	_, ok := a.resident(key)
+	return ok
+}
+
+func (a *ARC[K, V]) Put(key K, value V) error {
+	if a.capacity <= 0 {
+		a.stats.Insert()
+		a.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	n, ok := a.items[key]
+	if !ok {
+		a.stats.Insert()
+		a.insertNew(key, value)
+		return nil
+	}
+
+	switch n.Val.where {
+	case inT1, inT2:
+		old := n.Val.value
+		n = a.move(n, a.t2, inT2)
+		n.Val.value = value
+		a.evicted(key, old, cache.EvictReplaced)
+
+	case inB1:
+		// 近期性一侧的幽灵命中，扩大T1的目标大小
+		a.p = min(a.capacity, a.p+max(a.b2.Size()/a.b1.Size(), 1))
+		a.stats.Insert()
+		a.makeRoom(false)
+		n = a.move(n, a.t2, inT2)
+		n.Val.value = value
+
+	case inB2:
+		// 频率一侧的幽灵命中，缩小T1的目标大小
+		a.p = max(0, a.p-max(a.b1.Size()/a.b2.Size(), 1))
+		a.stats.Insert()
+		a.makeRoom(true)
+		n = a.move(n, a.t2, inT2)
+		n.Val.value = value
+	}
+
+	return nil
+}
+
+// insertNew 写入一个不在任何链表中的键
+func (a *ARC[K, V]) insertNew(key K, value V) {
+	c := a.capacity
+	l1 := a.t1.Size() + a.b1.Size()
+	total := l1 + a.t2.Size() + a.b2.Size()
+
+	switch {
+	case l1 >= c:
+		if a.t1.Size() < c {
+			a.dropGhost(a.b1)
+			a.makeRoom(false)
+		} else {
+			// B1为空且T1已满，直接淘汰T1中最久未访问的元素
+			e, _ := a.t1.RemoveHead()
+			delete(a.items, e.key)
+			a.evicted(e.key, e.value, cache.EvictCapacity)
+		}
+
+	case total >= c:
+		if total >= 2*c {
+			a.dropGhost(a.b2)
+		}
+		a.makeRoom(false)
+	}
+
+	a.items[key] = a.t1.Append(entry[K, V]{key: key, value: value, where: inT1})
+}
+
+// makeRoom 缓存已满时从T1或T2淘汰一个元素到对应的幽灵链表
+//
+// hitB2表示本次写入的键命中了B2。
+func (a *ARC[K, V]) makeRoom(hitB2 bool) bool {
+	if a.t1.Size()+a.t2.Size() < a.capacity {
+		return false
+	}
+
+	return a.replace(hitB2)
+}
+
+// replace 按目标大小p从T1或T2淘汰一个元素
+func (a *ARC[K, V]) replace(hitB2 bool) bool {
+	t1 := a.t1.Size()
+	if t1 > 0 && (t1 > a.p || (hitB2 && t1 == a.p) || a.t2.Size() == 0) {
+		return a.demote(a.t1, a.b1, inB1)
+	}
+
+	return a.demote(a.t2, a.b2, inB2)
+}
+
+// demote 将from中最久未访问的元素淘汰，并把它的键记录到幽灵链表ghost
+func (a *ARC[K, V]) demote(from, ghost *double_linked_list.DoubleLinkedList[entry[K, V]], where int) bool {
+	e, err := from.RemoveHead()
+	if err != nil {
+		return false
+	}
+
+	var zero V
+	a.items[e.key] = ghost.Append(entry[K, V]{key: e.key, value: zero, where: where})
+	a.evicted(e.key, e.value, cache.EvictCapacity)
+
+	return true
+}
+
+// dropGhost 删除幽灵链表中最久的键
+func (a *ARC[K, V]) dropGhost(ghost *double_linked_list.DoubleLinkedList[entry[K, V]]) {
+	e, err := ghost.RemoveHead()
+	if err == nil {
+		delete(a.items, e.key)
+	}
+}
+
+// Remove 删除缓存中的元素；幽灵链表中的键也会被清除，但返回false
+func (a *ARC[K, V]) Remove(key K) bool {
+	n, ok := a.items[key]
+	if !ok {
+		return false
+	}
+
+	e := n.Val
+	a.list(e.where).Remove(n)
+	delete(a.items, key)
+	if e.where == inB1 || e.where == inB2 {
+		return false
+	}
+
+	a.evicted(e.key, e.value, cache.EvictRemoved)
+	return true
+}
+
+// Len 返回缓存中元素的数量，不包括幽灵链表
+func (a *ARC[K, V]) Len() int {
+	return a.t1.Size() + a.t2.Size()
+}
+
+// Stats 返回统计信息，只有 Get 计入命中或未命中，幽灵链表中的键视为未命中
+func (a *ARC[K, V]) Stats() cache.Stats {
+	return a.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (a *ARC[K, V]) ResetStats() {
+	a.stats.Reset()
+}
+
+// Keys 返回缓存中的所有键，先T2后T1，各自按从最近到最久访问的顺序排列
+func (a *ARC[K, V]) Keys() []K {
+	keys := make([]K, 0, a.Len())
+	for key := range a.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// All 返回遍历缓存中所有元素的迭代器，顺序与 Keys 相同，遍历不改变淘汰顺序
+func (a *ARC[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, l := range []*double_linked_list.DoubleLinkedList[entry[K, V]]{a.t2, a.t1} {
+			for n := range l.Backward() {
+				if !yield(n.Val.key, n.Val.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clear 删除所有元素和幽灵键，并重置目标大小
+func (a *ARC[K, V]) Clear() {
+	t1, t2 := a.t1, a.t2
+	a.items = make(map[K]*node[K, V])
+	a.t1 = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	a.t2 = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	a.b1 = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	a.b2 = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	a.p = 0
+
+	for _, l := range []*double_linked_list.DoubleLinkedList[entry[K, V]]{t1, t2} {
+		for n := range l.Nodes() {
+			a.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+		}
+	}
+}
+
+// Resize 修改容量，容量缩小时按ARC的规则淘汰多出的元素并裁剪幽灵链表，返回淘汰的数量
+func (a *ARC[K, V]) Resize(capacity int) int {
+	a.capacity = max(capacity, 0)
+	a.p = min(a.p, a.capacity)
+
+	evicted := 0
+	for a.Len() > a.capacity && a.replace(false) {
+		evicted++
+	}
+
+	for a.t1.Size()+a.b1.Size() > a.capacity && a.b1.Size() > 0 {
+		a.dropGhost(a.b1)
+	}
+	for a.Len()+a.b1.Size()+a.b2.Size() > 2*a.capacity && a.b2.Size() > 0 {
+		a.dropGhost(a.b2)
+	}
+
+	return evicted
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+//
+// 只有常驻元素离开缓存时触发回调，幽灵键被丢弃时不触发。
+func (a *ARC[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	a.onEvict = fn
+}
+
+// resident 返回在T1或T2中的节点
+func (a *ARC[K, V]) resident(key K) (*node[K, V], bool) {
+	n, ok := a.items[key]
+	if !ok || n.Val.where == inB1 || n.Val.where == inB2 {
+		return nil, false
+	}
+
+	return n, true
+}
+
+// move 将节点移动到链表to的尾部，返回新的节点
+func (a *ARC[K, V]) move(n *node[K, V], to *double_linked_list.DoubleLinkedList[entry[K, V]], where int) *node[K, V] {
+	from := a.list(n.Val.where)
+	if from == to {
+		to.MoveToTail(n)
+		return n
+	}
+
+	e := n.Val
+	from.Remove(n)
+	e.where = where
+	moved := to.Append(e)
+	a.items[e.key] = moved
+
+	return moved
+}
+
+func (a *ARC[K, V]) list(where int) *double_linked_list.DoubleLinkedList[entry[K, V]] {
+	switch where {
+	case inT1:
+		return a.t1
+	case inT2:
+		return a.t2
+	case inB1:
+		return a.b1
+	default:
+		return a.b2
+	}
+}
+
+func (a *ARC[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	a.stats.Evict(reason)
+	if a.onEvict != nil {
+		a.onEvict(key, value, reason)
+	}
+}
@@ -0,0 +1,241 @@
+package arc
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ARCTestSuite 是ARC缓存的测试套件
+type ARCTestSuite struct {
+	suite.Suite
+	arc *ARC[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *ARCTestSuite) SetupTest() {
+	s.arc = NewARC[string, int](3)
+}
+
+// TestPromoteToT2 测试第二次访问把元素从T1移动到T2
+func (s *ARCTestSuite) TestPromoteToT2() {
+	s.NoError(s.arc.Put("key1", 1))
+	s.Equal(inT1, s.arc.items["key1"].Val.where)
+
+	val, ok := s.arc.Get("key1")
+	s.True(ok)
+	s.Equal(1, val)
+	s.Equal(inT2, s.arc.items["key1"].Val.where)
+	s.Equal(0, s.arc.t1.Size())
+	s.Equal(1, s.arc.t2.Size())
+}
+
+// TestGhostHit 测试被淘汰的键进入幽灵链表，再次写入时调整目标大小
+func (s *ARCTestSuite) TestGhostHit() {
+	s.NoError(s.arc.Put("key1", 1))
+	s.NoError(s.arc.Put("key2", 2))
+	s.NoError(s.arc.Put("key3", 3))
+	s.arc.Get("key3")
+
+	// T1中最久的key1被淘汰到B1
+	s.NoError(s.arc.Put("key4", 4))
+	s.False(s.arc.Contains("key1"))
+	s.Equal(inB1, s.arc.items["key1"].Val.where)
+	s.Equal(3, s.arc.Len())
+
+	// B1命中，p增大，key1直接进入T2
+	s.NoError(s.arc.Put("key1", 10))
+	s.Equal(1, s.arc.p)
+	s.Equal(inT2, s.arc.items["key1"].Val.where)
+	val, ok := s.arc.Peek("key1")
+	s.True(ok)
+	s.Equal(10, val)
+	s.Equal(3, s.arc.Len())
+}
+
+// TestB2Hit 测试B2命中时缩小T1的目标大小
+func (s *ARCTestSuite) TestB2Hit() {
+	a := NewARC[string, int](2)
+	s.NoError(a.Put("key1", 1))
+	a.Get("key1")
+	s.NoError(a.Put("key2", 2))
+	a.Get("key2")
+
+	// T1为空，淘汰T2中最久的key1到B2
+	s.NoError(a.Put("key3", 3))
+	s.Equal(inB2, a.items["key1"].Val.where)
+
+	// B2命中，p不低于0，T1中的key3被淘汰到B1
+	s.NoError(a.Put("key1", 1))
+	s.Equal(0, a.p)
+	s.Equal(inT2, a.items["key1"].Val.where)
+	s.Equal(inB1, a.items["key3"].Val.where)
+	s.Equal(2, a.Len())
+}
+
+// TestGhostBounded 测试幽灵链表的大小有上限
+func (s *ARCTestSuite) TestGhostBounded() {
+	for i := range 100 {
+		s.NoError(s.arc.Put(strconv.Itoa(i), i))
+		if i%3 == 0 {
+			s.arc.Get(strconv.Itoa(i))
+		}
+	}
+
+	s.Equal(3, s.arc.Len())
+	s.LessOrEqual(s.arc.t1.Size()+s.arc.b1.Size(), 3)
+	s.LessOrEqual(len(s.arc.items), 6)
+}
+
+// TestRemoveGhost 测试删除幽灵键返回false
+func (s *ARCTestSuite) TestRemoveGhost() {
+	s.NoError(s.arc.Put("0", 0))
+	s.NoError(s.arc.Put("1", 1))
+	s.NoError(s.arc.Put("2", 2))
+	s.arc.Get("2")
+	s.NoError(s.arc.Put("3", 3))
+	s.Equal(inB1, s.arc.items["0"].Val.where)
+
+	s.False(s.arc.Remove("0"))
+	_, ok := s.arc.items["0"]
+	s.False(ok)
+}
+
+// TestOnEvict 测试通过 OnEvict 设置和取消回调
+func (s *ARCTestSuite) TestOnEvict() {
+	got := make(map[string]cache.EvictReason)
+	s.arc.OnEvict(func(key string, _ int, reason cache.EvictReason) {
+		got[key] = reason
+	})
+
+	for i := range 4 {
+		s.NoError(s.arc.Put(strconv.Itoa(i), i))
+	}
+	s.True(s.arc.Remove("1"))
+	s.Equal(map[string]cache.EvictReason{"0": cache.EvictCapacity, "1": cache.EvictRemoved}, got)
+
+	s.arc.OnEvict(nil)
+	s.True(s.arc.Remove("2"))
+	s.Len(got, 2)
+}
+
+// TestAll 测试All先T2后T1遍历，遍历期间可以删除当前元素
+func (s *ARCTestSuite) TestAll() {
+	s.NoError(s.arc.Put("key1", 1))
+	s.NoError(s.arc.Put("key2", 2))
+	s.NoError(s.arc.Put("key3", 3))
+	s.arc.Get("key1")
+
+	var keys []string
+	for key, value := range s.arc.All() {
+		keys = append(keys, key)
+		if value == 2 {
+			s.True(s.arc.Remove(key))
+		}
+	}
+	s.Equal([]string{"key1", "key3", "key2"}, keys)
+	s.Equal([]string{"key1", "key3"}, s.arc.Keys())
+	s.Equal(inT2, s.arc.items["key1"].Val.where)
+}
+
+// TestScanResistance 测试一次性扫描不会冲刷热点数据，而LRU会
+func (s *ARCTestSuite) TestScanResistance() {
+	const capacity = 100
+	a := NewARC[string, int](capacity)
+	l := lru.NewLRU[string, int](capacity)
+
+	access := func(key string) {
+		if _, ok := a.Get(key); !ok {
+			a.Put(key, 0)
+		}
+		if _, ok := l.Get(key); !ok {
+			l.Put(key, 0)
+		}
+	}
+
+	// 热点数据被访问多次
+	for range 3 {
+		for i := range 50 {
+			access("hot" + strconv.Itoa(i))
+		}
+	}
+
+	// 一次性扫描大量冷数据
+	for i := range 1000 {
+		access("scan" + strconv.Itoa(i))
+	}
+
+	arcHits, lruHits := 0, 0
+	for i := range 50 {
+		key := "hot" + strconv.Itoa(i)
+		if a.Contains(key) {
+			arcHits++
+		}
+		if l.Contains(key) {
+			lruHits++
+		}
+	}
+
+	s.Equal(50, arcHits)
+	s.Equal(0, lruHits)
+}
+
+// TestStats 测试命中、未命中、写入、更新和淘汰的统计，幽灵命中计为一次写入
+func (s *ARCTestSuite) TestStats() {
+	s.arc.Put("key1", 1)
+	s.arc.Put("key1", 10)
+	s.arc.Put("key2", 2)
+	s.arc.Put("key3", 3)
+	s.arc.Get("key1")
+	s.arc.Get("key3")
+	s.arc.Get("key9")
+	s.arc.Peek("key2")
+	s.arc.Put("key4", 4)
+	s.arc.Remove("key4")
+
+	st := s.arc.Stats()
+	s.Equal(uint64(2), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(4), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(uint64(1), st.Evictions[cache.EvictRemoved])
+	s.Equal(2, st.Size)
+	s.InDelta(2.0/3, st.HitRatio(), 1e-9)
+
+	// key2已被淘汰到B1，再次写入时重新进入缓存
+	s.arc.Put("key2", 20)
+	st = s.arc.Stats()
+	s.Equal(uint64(5), st.Insertions)
+	s.Equal(s.arc.Len(), st.Size)
+
+	s.arc.ResetStats()
+	st = s.arc.Stats()
+	s.Zero(st.Hits)
+	s.Zero(st.Evicted())
+	s.Equal(3, st.Size)
+
+	// 容量为0时写入后立即被淘汰
+	a := NewARC[string, int](0)
+	a.Put("key1", 1)
+	st = a.Stats()
+	s.Equal(uint64(1), st.Insertions)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(0, st.Size)
+}
+
+// TestARC 运行所有ARC测试
+func TestARC(t *testing.T) {
+	suite.Run(t, new(ARCTestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
@@ -0,0 +1,27 @@
+package cache
+
+// Cache 是各缓存策略共同实现的接口
+//
+// 各缓存策略还提供 All 方法，返回遍历所有元素的迭代器，遍历顺序见各自的文档。
+// 遍历期间可以随时停止，也可以调用 Remove 删除当前元素；其他修改缓存的操作
+// （包括会调整淘汰顺序的 Get）可能导致元素被重复或遗漏，甚至panic，应在遍历结束后执行。
+type Cache[K comparable, V any] interface {
+	// Get 返回key对应的值，并按策略记录这次访问
+	Get(key K) (V, bool)
+	// Peek 返回key对应的值，不影响淘汰顺序
+	Peek(key K) (V, bool)
+	// Put 写入元素，容量不足时按策略淘汰
+	Put(key K, value V) error
+	// Remove 删除元素，返回元素是否存在
+	Remove(key K) bool
+	// Contains 判断元素是否存在，不影响淘汰顺序
+	Contains(key K) bool
+	// Len 返回元素数量
+	Len() int
+	// Clear 删除所有元素
+	Clear()
+	// Keys 返回所有键
+	Keys() []K
+	// Resize 修改容量，容量缩小时立即淘汰多出的元素，返回淘汰的数量
+	Resize(capacity int) int
+}
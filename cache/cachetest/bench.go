@@ -0,0 +1,67 @@
+package cachetest
+
+import (
+	"algorithm/cache"
+	"math/rand/v2"
+	"testing"
+)
+
+// benchKeys 是并发读取基准测试中缓存的容量和键的数量
+const benchKeys = 1024
+
+// BenchmarkConcurrentGet 测量并发命中时的吞吐量
+//
+// newCache按容量构造缓存，写满之后由多个协程并发读取其中的键。各策略通常
+// 同时对加锁的LRU运行一次作为对照：
+//
+//	func BenchmarkConcurrentGet(b *testing.B) {
+//		b.Run("sieve", func(b *testing.B) {
+//			cachetest.BenchmarkConcurrentGet(b, func(capacity int) cache.Cache[int, int] {
+//				return NewSIEVE[int, int](capacity)
+//			})
+//		})
+//		...
+//	}
+func BenchmarkConcurrentGet(b *testing.B, newCache func(capacity int) cache.Cache[int, int]) {
+	c := newCache(benchKeys)
+	for i := range benchKeys {
+		c.Put(i, i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.Get(i % benchKeys)
+			i++
+		}
+	})
+}
+
+// ZipfTrace 生成长度为n、参数为s的Zipf分布访问序列，键的取值范围是[0, keys)
+//
+// 相同的seed生成相同的序列，用于比较不同策略的命中率。
+func ZipfTrace(seed uint64, s float64, keys uint64, n int) []int {
+	r := rand.New(rand.NewPCG(seed, seed))
+	z := rand.NewZipf(r, s, 1, keys-1)
+	trace := make([]int, n)
+	for i := range trace {
+		trace[i] = int(z.Uint64())
+	}
+
+	return trace
+}
+
+// HitRatio 依次访问trace中的键，未命中时写入，返回命中率
+func HitRatio(c cache.Cache[int, int], trace []int) float64 {
+	hits := 0
+	for _, key := range trace {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Put(key, key)
+	}
+
+	return float64(hits) / float64(len(trace))
+}
@@ -0,0 +1,174 @@
+// Package cachetest 提供所有 cache.Cache 实现都应通过的一致性测试，以及各策略共用的基准测试和命中率测试工具
+//
+// 各策略在自己的测试中调用 Run，传入以 cache.Option 构造缓存的函数即可：
+//
+//	func TestConformance(t *testing.T) {
+//		cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+//			return New[string, int](opts...)
+//		})
+//	}
+package cachetest
+
+import (
+	"algorithm/cache"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// Evicted 记录一次淘汰回调
+type Evicted struct {
+	Key    string
+	Value  int
+	Reason cache.EvictReason
+}
+
+// Suite 缓存策略的一致性测试套件
+type Suite struct {
+	suite.Suite
+	New     func(opts ...cache.Option) cache.Cache[string, int]
+	cache   cache.Cache[string, int]
+	evicted []Evicted
+}
+
+// Run 对newCache构造的缓存运行一致性测试
+func Run(t *testing.T, newCache func(opts ...cache.Option) cache.Cache[string, int]) {
+	suite.Run(t, &Suite{New: newCache})
+}
+
+// SetupTest 在每个测试用例之前执行，创建一个容量为4并记录淘汰回调的缓存
+func (s *Suite) SetupTest() {
+	s.evicted = nil
+	s.cache = s.New(
+		cache.WithCapacity(4),
+		cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+			s.evicted = append(s.evicted, Evicted{key, value, reason})
+		}),
+	)
+}
+
+func (s *Suite) fill(n int) {
+	for i := range n {
+		s.NoError(s.cache.Put(strconv.Itoa(i), i))
+	}
+}
+
+func (s *Suite) countEvicted(reason cache.EvictReason) int {
+	n := 0
+	for _, e := range s.evicted {
+		if e.Reason == reason {
+			n++
+		}
+	}
+
+	return n
+}
+
+// TestPutAndGet 测试基本的Put和Get操作
+func (s *Suite) TestPutAndGet() {
+	s.NoError(s.cache.Put("key1", 1))
+
+	val, ok := s.cache.Get("key1")
+	s.True(ok)
+	s.Equal(1, val)
+
+	val, ok = s.cache.Get("key2")
+	s.False(ok)
+	s.Equal(0, val)
+}
+
+// TestPeekAndContains 测试Peek和Contains不改变缓存内容
+func (s *Suite) TestPeekAndContains() {
+	s.NoError(s.cache.Put("key1", 1))
+
+	val, ok := s.cache.Peek("key1")
+	s.True(ok)
+	s.Equal(1, val)
+	s.True(s.cache.Contains("key1"))
+
+	val, ok = s.cache.Peek("key2")
+	s.False(ok)
+	s.Equal(0, val)
+	s.False(s.cache.Contains("key2"))
+
+	s.Equal(1, s.cache.Len())
+}
+
+// TestUpdate 测试覆盖写入
+func (s *Suite) TestUpdate() {
+	s.NoError(s.cache.Put("key1", 1))
+	s.NoError(s.cache.Put("key1", 10))
+
+	val, ok := s.cache.Get("key1")
+	s.True(ok)
+	s.Equal(10, val)
+	s.Equal(1, s.cache.Len())
+	s.Equal([]Evicted{{"key1", 1, cache.EvictReplaced}}, s.evicted)
+}
+
+// TestRemove 测试删除元素
+func (s *Suite) TestRemove() {
+	s.fill(2)
+
+	s.True(s.cache.Remove("0"))
+	s.False(s.cache.Remove("0"))
+	s.False(s.cache.Contains("0"))
+	s.Equal(1, s.cache.Len())
+	s.Equal([]Evicted{{"0", 0, cache.EvictRemoved}}, s.evicted)
+}
+
+// TestCapacity 测试元素数量不超过容量，且淘汰会触发回调
+func (s *Suite) TestCapacity() {
+	s.fill(10)
+
+	s.Equal(4, s.cache.Len())
+	s.Len(s.cache.Keys(), 4)
+	s.Equal(6, s.countEvicted(cache.EvictCapacity))
+
+	// 刚写入的元素不会被立即淘汰
+	val, ok := s.cache.Get("9")
+	s.True(ok)
+	s.Equal(9, val)
+}
+
+// TestKeys 测试Keys返回所有键
+func (s *Suite) TestKeys() {
+	s.Empty(s.cache.Keys())
+
+	s.fill(3)
+	s.ElementsMatch([]string{"0", "1", "2"}, s.cache.Keys())
+	for _, key := range s.cache.Keys() {
+		s.True(s.cache.Contains(key))
+	}
+}
+
+// TestClear 测试清空缓存
+func (s *Suite) TestClear() {
+	s.fill(3)
+	s.cache.Clear()
+
+	s.Equal(0, s.cache.Len())
+	s.Empty(s.cache.Keys())
+	s.False(s.cache.Contains("0"))
+	s.Equal(3, s.countEvicted(cache.EvictRemoved))
+
+	// 清空后可以继续使用
+	s.NoError(s.cache.Put("key1", 1))
+	s.True(s.cache.Contains("key1"))
+}
+
+// TestResize 测试修改容量
+func (s *Suite) TestResize() {
+	s.fill(4)
+
+	s.Equal(2, s.cache.Resize(2))
+	s.Equal(2, s.cache.Len())
+	s.Equal(2, s.countEvicted(cache.EvictCapacity))
+
+	s.Equal(0, s.cache.Resize(8))
+	for i := range 8 {
+		s.NoError(s.cache.Put("new"+strconv.Itoa(i), i))
+	}
+	s.Equal(8, s.cache.Len())
+}
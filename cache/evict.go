@@ -0,0 +1,67 @@
+// Package cache 定义各缓存策略共用的类型
+package cache
+
+import "sync"
+
+// EvictReason 元素离开缓存的原因
+type EvictReason int
+
+const (
+	EvictCapacity EvictReason = iota // 容量不足被淘汰
+	EvictRemoved                     // 被 Remove 显式删除
+	EvictReplaced                    // 被 Put 写入的新值覆盖
+	EvictExpired                     // 已过期
+)
+
+func (r EvictReason) String() string {
+	switch r {
+	case EvictCapacity:
+		return "capacity"
+	case EvictRemoved:
+		return "removed"
+	case EvictReplaced:
+		return "replaced"
+	case EvictExpired:
+		return "expired"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 以 String 的结果编码，使其作为JSON对象的键时更易读
+func (r EvictReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+type evicted[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictReason
+}
+
+// PendingEvictions 积攒持锁期间离开缓存的元素，在释放锁之后再执行淘汰回调，
+// 使回调中可以再次访问缓存
+//
+// 零值即可使用。PendingEvictions 本身不加锁，需要由缓存的锁保护。
+type PendingEvictions[K comparable, V any] struct {
+	items []evicted[K, V]
+}
+
+// Add 记录一个离开缓存的元素
+func (p *PendingEvictions[K, V]) Add(key K, value V, reason EvictReason) {
+	p.items = append(p.items, evicted[K, V]{key, value, reason})
+}
+
+// Unlock 取出积攒的元素后释放mu，再在锁外依次调用fn；fn为nil时丢弃积攒的元素
+func (p *PendingEvictions[K, V]) Unlock(mu sync.Locker, fn func(key K, value V, reason EvictReason)) {
+	items := p.items
+	p.items = nil
+	mu.Unlock()
+
+	if fn == nil {
+		return
+	}
+	for _, e := range items {
+		fn(e.key, e.value, e.reason)
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestEvictReasonString(t *testing.T) {
+	data := []struct {
+		reason   EvictReason
+		expected string
+	}{
+		{EvictCapacity, "capacity"},
+		{EvictRemoved, "removed"},
+		{EvictReplaced, "replaced"},
+		{EvictExpired, "expired"},
+		{EvictReason(-1), "unknown"},
+	}
+
+	for _, d := range data {
+		if got := d.reason.String(); got != d.expected {
+			t.Errorf("EvictReason(%d).String() = %q, want %q", int(d.reason), got, d.expected)
+		}
+	}
+}
+
+func TestEvictReasonMarshalText(t *testing.T) {
+	got, err := json.Marshal(map[EvictReason]int{EvictCapacity: 1, EvictExpired: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"capacity":1,"expired":2}`; string(got) != want {
+		t.Errorf("json.Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestPendingEvictions(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		pending PendingEvictions[string, int]
+		got     []string
+	)
+	fn := func(key string, value int, reason EvictReason) {
+		// 回调在锁外执行
+		if !mu.TryLock() {
+			t.Fatal("callback runs while holding the lock")
+		}
+		mu.Unlock()
+		got = append(got, key+"="+reason.String())
+	}
+
+	mu.Lock()
+	pending.Add("a", 1, EvictCapacity)
+	pending.Add("b", 2, EvictRemoved)
+	pending.Unlock(&mu, fn)
+	if want := []string{"a=capacity", "b=removed"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// 回调已执行的元素不会再次回调，fn为nil时只释放锁
+	got = nil
+	mu.Lock()
+	pending.Add("c", 3, EvictExpired)
+	pending.Unlock(&mu, nil)
+	mu.Lock()
+	pending.Unlock(&mu, fn)
+	if len(got) != 0 {
+		t.Errorf("got %v, want none", got)
+	}
+}
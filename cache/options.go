@@ -0,0 +1,86 @@
+package cache
+
+import "algorithm/clock"
+
+// Options 缓存的构造参数
+//
+// 各策略共用的参数以字段形式给出；策略私有的参数由各自的包通过
+// SetValue/Value 按键存取，键应使用包内未导出的类型以避免冲突。
+type Options struct {
+	Capacity int
+	Clock    clock.Clock
+	Codec    Codec // 快照的编解码方式
+	onEvict  any
+	values   map[any]any
+}
+
+// Option 缓存的构造选项
+type Option func(*Options)
+
+// NewOptions 依次应用opts，返回最终的构造参数
+func NewOptions(opts ...Option) *Options {
+	o := &Options{Clock: clock.Real, Codec: GobCodec}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithCapacity 指定缓存容量
+func WithCapacity(capacity int) Option {
+	return func(o *Options) {
+		o.Capacity = capacity
+	}
+}
+
+// WithClock 指定缓存使用的时钟，默认为系统时钟
+func WithClock(c clock.Clock) Option {
+	return func(o *Options) {
+		o.Clock = c
+	}
+}
+
+// WithCodec 指定快照的编解码方式，默认为 GobCodec
+func WithCodec(c Codec) Option {
+	return func(o *Options) {
+		o.Codec = c
+	}
+}
+
+// WithOnEvict 指定元素离开缓存时的回调，其键值类型必须与缓存一致
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictReason)) Option {
+	return func(o *Options) {
+		o.onEvict = fn
+	}
+}
+
+// OnEvict 返回通过 WithOnEvict 指定的回调，未指定时返回nil
+//
+// 回调的键值类型与K、V不一致时panic。
+func OnEvict[K comparable, V any](o *Options) func(key K, value V, reason EvictReason) {
+	if o.onEvict == nil {
+		return nil
+	}
+
+	fn, ok := o.onEvict.(func(K, V, EvictReason))
+	if !ok {
+		panic("cache: the key/value types of WithOnEvict do not match the cache")
+	}
+
+	return fn
+}
+
+// SetValue 保存策略私有的参数
+func (o *Options) SetValue(key, value any) {
+	if o.values == nil {
+		o.values = make(map[any]any)
+	}
+
+	o.values[key] = value
+}
+
+// Value 返回策略私有的参数，未设置时返回nil
+func (o *Options) Value(key any) any {
+	return o.values[key]
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"algorithm/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// OptionsTestSuite 是构造选项的测试套件
+type OptionsTestSuite struct {
+	suite.Suite
+}
+
+// TestDefaults 测试默认参数
+func (s *OptionsTestSuite) TestDefaults() {
+	o := NewOptions()
+	s.Equal(0, o.Capacity)
+	s.Equal(clock.Real, o.Clock)
+	s.Nil(OnEvict[string, int](o))
+	s.Nil(o.Value("missing"))
+}
+
+// TestWithOptions 测试应用选项
+func (s *OptionsTestSuite) TestWithOptions() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	var called bool
+	o := NewOptions(
+		WithCapacity(8),
+		WithClock(clk),
+		WithOnEvict(func(key string, value int, reason EvictReason) {
+			called = true
+		}),
+	)
+
+	s.Equal(8, o.Capacity)
+	s.Equal(clk, o.Clock)
+
+	fn := OnEvict[string, int](o)
+	s.NotNil(fn)
+	fn("key", 1, EvictCapacity)
+	s.True(called)
+
+	// 后面的选项覆盖前面的
+	o = NewOptions(WithCapacity(8), WithCapacity(4))
+	s.Equal(4, o.Capacity)
+}
+
+// TestOnEvictTypeMismatch 测试回调类型不匹配时panic
+func (s *OptionsTestSuite) TestOnEvictTypeMismatch() {
+	o := NewOptions(WithOnEvict(func(key int, value int, reason EvictReason) {}))
+	s.Panics(func() {
+		OnEvict[string, int](o)
+	})
+}
+
+// TestValue 测试策略私有参数
+func (s *OptionsTestSuite) TestValue() {
+	type key struct{}
+	o := NewOptions(func(o *Options) {
+		o.SetValue(key{}, time.Second)
+	})
+	s.Equal(time.Second, o.Value(key{}))
+}
+
+// TestOptions 运行所有构造选项测试
+func TestOptions(t *testing.T) {
+	suite.Run(t, new(OptionsTestSuite))
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// SnapshotVersion 是当前快照格式的版本号
+const SnapshotVersion = 1
+
+// snapshotMagic 是快照文件开头的魔数
+var snapshotMagic = [4]byte{'A', 'C', 'S', 'N'}
+
+var (
+	// ErrBadSnapshot 表示快照格式不正确或已损坏
+	ErrBadSnapshot = errors.New("cache: bad snapshot")
+	// ErrSnapshotVersion 表示快照的版本不受支持
+	ErrSnapshotVersion = errors.New("cache: unsupported snapshot version")
+)
+
+// Encoder 把值编码后写入底层的 io.Writer
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder 从底层的 io.Reader 读取并解码值
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec 是快照内容的编解码方式
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+var (
+	// GobCodec 使用 encoding/gob 编解码，是默认的编解码方式
+	GobCodec Codec = gobCodec{}
+	// JSONCodec 使用 encoding/json 编解码，便于人工查看快照内容
+	JSONCodec Codec = jsonCodec{}
+)
+
+// WriteSnapshot 用codec编码v，并加上文件头和校验和写入w
+//
+// 快照依次包含魔数、版本号、缓存类型kind、内容长度、内容的CRC32校验和以及内容本身。
+func WriteSnapshot(w io.Writer, codec Codec, kind string, v any) error {
+	if len(kind) > 255 {
+		return fmt.Errorf("cache: snapshot kind %q is too long", kind)
+	}
+
+	var payload bytes.Buffer
+	if err := codec.NewEncoder(&payload).Encode(v); err != nil {
+		return fmt.Errorf("cache: encode snapshot: %w", err)
+	}
+
+	header := make([]byte, 0, 4+2+1+len(kind)+8+4)
+	header = append(header, snapshotMagic[:]...)
+	header = binary.BigEndian.AppendUint16(header, SnapshotVersion)
+	header = append(header, byte(len(kind)))
+	header = append(header, kind...)
+	header = binary.BigEndian.AppendUint64(header, uint64(payload.Len()))
+	header = binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(payload.Bytes()))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload.Bytes())
+
+	return err
+}
+
+// ReadSnapshot 读取 WriteSnapshot 写入的快照，校验后用codec解码到v
+//
+// 魔数、缓存类型、长度或校验和不符时返回 ErrBadSnapshot，版本不受支持时返回 ErrSnapshotVersion。
+func ReadSnapshot(r io.Reader, codec Codec, kind string, v any) error {
+	var prefix [4 + 2 + 1]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return fmt.Errorf("%w: read header: %v", ErrBadSnapshot, err)
+	}
+	if [4]byte(prefix[:4]) != snapshotMagic {
+		return fmt.Errorf("%w: bad magic", ErrBadSnapshot)
+	}
+	if version := binary.BigEndian.Uint16(prefix[4:6]); version != SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrSnapshotVersion, version)
+	}
+
+	rest := make([]byte, int(prefix[6])+8+4)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return fmt.Errorf("%w: read header: %v", ErrBadSnapshot, err)
+	}
+	if got := string(rest[:prefix[6]]); got != kind {
+		return fmt.Errorf("%w: snapshot of %q, want %q", ErrBadSnapshot, got, kind)
+	}
+	rest = rest[prefix[6]:]
+	size := binary.BigEndian.Uint64(rest[:8])
+	sum := binary.BigEndian.Uint32(rest[8:])
+
+	// 不信任长度字段，避免损坏的快照导致一次分配过多内存
+	payload, err := io.ReadAll(io.LimitReader(r, int64(min(size, 1<<62))))
+	if err != nil {
+		return fmt.Errorf("%w: read payload: %v", ErrBadSnapshot, err)
+	}
+	if uint64(len(payload)) != size {
+		return fmt.Errorf("%w: truncated payload", ErrBadSnapshot)
+	}
+	if crc32.ChecksumIEEE(payload) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrBadSnapshot)
+	}
+
+	if err := codec.NewDecoder(bytes.NewReader(payload)).Decode(v); err != nil {
+		return fmt.Errorf("%w: decode: %v", ErrBadSnapshot, err)
+	}
+
+	return nil
+}
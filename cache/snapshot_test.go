@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SnapshotTestSuite 是快照格式的测试套件
+type SnapshotTestSuite struct {
+	suite.Suite
+}
+
+type payload struct {
+	Name  string
+	Items []int
+}
+
+// TestRoundTrip 测试两种编解码方式写入后可以原样读出
+func (s *SnapshotTestSuite) TestRoundTrip() {
+	for _, codec := range []Codec{GobCodec, JSONCodec} {
+		var buf bytes.Buffer
+		want := payload{Name: "test", Items: []int{1, 2, 3}}
+		s.NoError(WriteSnapshot(&buf, codec, "kind", want))
+
+		var got payload
+		s.NoError(ReadSnapshot(&buf, codec, "kind", &got))
+		s.Equal(want, got)
+	}
+}
+
+// TestCorrupted 测试损坏的快照被拒绝
+func (s *SnapshotTestSuite) TestCorrupted() {
+	var buf bytes.Buffer
+	s.NoError(WriteSnapshot(&buf, GobCodec, "kind", payload{Name: "test"}))
+	data := buf.Bytes()
+
+	read := func(b []byte, kind string) error {
+		var got payload
+		return ReadSnapshot(bytes.NewReader(b), GobCodec, kind, &got)
+	}
+
+	// 内容被修改
+	flipped := bytes.Clone(data)
+	flipped[len(flipped)-1] ^= 0xff
+	s.ErrorIs(read(flipped, "kind"), ErrBadSnapshot)
+
+	// 内容被截断
+	s.ErrorIs(read(data[:len(data)-1], "kind"), ErrBadSnapshot)
+	s.ErrorIs(read(data[:3], "kind"), ErrBadSnapshot)
+
+	// 魔数错误
+	magic := bytes.Clone(data)
+	magic[0] = 'X'
+	s.ErrorIs(read(magic, "kind"), ErrBadSnapshot)
+
+	// 缓存类型不符
+	s.ErrorIs(read(data, "other"), ErrBadSnapshot)
+
+	// 版本不受支持
+	version := bytes.Clone(data)
+	binary.BigEndian.PutUint16(version[4:], SnapshotVersion+1)
+	s.ErrorIs(read(version, "kind"), ErrSnapshotVersion)
+
+	// 编解码方式不符
+	var got payload
+	s.ErrorIs(ReadSnapshot(bytes.NewReader(data), JSONCodec, "kind", &got), ErrBadSnapshot)
+}
+
+// TestWithCodec 测试指定快照的编解码方式
+func (s *SnapshotTestSuite) TestWithCodec() {
+	s.Equal(GobCodec, NewOptions().Codec)
+	s.Equal(JSONCodec, NewOptions(WithCodec(JSONCodec)).Codec)
+}
+
+// TestSnapshot 运行所有快照格式测试
+func TestSnapshot(t *testing.T) {
+	suite.Run(t, new(SnapshotTestSuite))
+}
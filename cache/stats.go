@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// evictReasons 是 EvictReason 取值的个数
+const evictReasons = int(EvictExpired) + 1
+
+// Stats 是缓存统计信息的快照
+type Stats struct {
+	Hits       uint64                 // Get 命中次数
+	Misses     uint64                 // Get 未命中次数，包括命中已过期的元素
+	Insertions uint64                 // 写入新键的次数
+	Updates    uint64                 // 覆盖已有键的次数
+	Evictions  map[EvictReason]uint64 // 按原因统计的离开缓存的元素数量，不含 EvictReplaced
+	Size       int                    // 当前元素数量
+}
+
+// HitRatio 返回命中率，没有任何 Get 时返回0
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return float64(s.Hits) / float64(total)
+}
+
+// Evicted 返回离开缓存的元素总数
+func (s Stats) Evicted() uint64 {
+	var n uint64
+	for _, c := range s.Evictions {
+		n += c
+	}
+
+	return n
+}
+
+// StatsCounter 用原子计数器累计缓存的统计信息，可以在不持有缓存锁的情况下读取
+//
+// 各缓存策略的 Stats 方法都基于 StatsCounter，因此可以在其他协程使用缓存的同时调用。
+// 零值即可使用。
+type StatsCounter struct {
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	insertions atomic.Uint64
+	updates    atomic.Uint64
+	evictions  [evictReasons]atomic.Uint64
+	size       atomic.Int64
+}
+
+// Hit 记录一次命中
+func (c *StatsCounter) Hit() {
+	c.hits.Add(1)
+}
+
+// Miss 记录一次未命中
+func (c *StatsCounter) Miss() {
+	c.misses.Add(1)
+}
+
+// Insert 记录写入了一个新键
+func (c *StatsCounter) Insert() {
+	c.insertions.Add(1)
+	c.size.Add(1)
+}
+
+// Evict 记录一个元素离开缓存
+//
+// EvictReplaced 表示键仍在缓存中，只是值被覆盖，因此记为一次更新。
+func (c *StatsCounter) Evict(reason EvictReason) {
+	if reason == EvictReplaced {
+		c.updates.Add(1)
+		return
+	}
+
+	if reason >= 0 && int(reason) < evictReasons {
+		c.evictions[reason].Add(1)
+	}
+	c.size.Add(-1)
+}
+
+// Snapshot 返回当前的统计信息
+//
+// 各计数器分别读取，并发写入时快照中的数值之间可能略有出入。
+func (c *StatsCounter) Snapshot() Stats {
+	s := Stats{
+		Hits:       c.hits.Load(),
+		Misses:     c.misses.Load(),
+		Insertions: c.insertions.Load(),
+		Updates:    c.updates.Load(),
+		Evictions:  make(map[EvictReason]uint64, evictReasons),
+		Size:       int(c.size.Load()),
+	}
+	for i := range c.evictions {
+		if reason := EvictReason(i); reason != EvictReplaced {
+			s.Evictions[reason] = c.evictions[i].Load()
+		}
+	}
+
+	return s
+}
+
+// Reset 将各计数器清零，当前元素数量保持不变
+func (c *StatsCounter) Reset() {
+	c.hits.Store(0)
+	c.misses.Store(0)
+	c.insertions.Store(0)
+	c.updates.Store(0)
+	for i := range c.evictions {
+		c.evictions[i].Store(0)
+	}
+}
+
+// StatsReporter 是可以报告统计信息的缓存
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// StatsVar 返回一个 expvar.Var，每次读取时以JSON形式输出r的统计信息
+func StatsVar(r StatsReporter) expvar.Var {
+	return expvar.Func(func() any {
+		s := r.Stats()
+		return map[string]any{
+			"hits":       s.Hits,
+			"misses":     s.Misses,
+			"insertions": s.Insertions,
+			"updates":    s.Updates,
+			"evictions":  s.Evictions,
+			"size":       s.Size,
+			"hit_ratio":  s.HitRatio(),
+		}
+	})
+}
+
+// Publish 以name为名把r的统计信息发布到 expvar，可通过 /debug/vars 读取
+//
+// 与 expvar.Publish 相同，name重复时panic。
+func Publish(name string, r StatsReporter) {
+	expvar.Publish(name, StatsVar(r))
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// StatsTestSuite 是统计计数器的测试套件
+type StatsTestSuite struct {
+	suite.Suite
+}
+
+// TestCounter 测试各计数器的累计
+func (s *StatsTestSuite) TestCounter() {
+	var c StatsCounter
+	c.Hit()
+	c.Hit()
+	c.Hit()
+	c.Miss()
+	c.Insert()
+	c.Insert()
+	c.Insert()
+	c.Evict(EvictReplaced)
+	c.Evict(EvictCapacity)
+	c.Evict(EvictExpired)
+
+	st := c.Snapshot()
+	s.Equal(uint64(3), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(3), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(map[EvictReason]uint64{EvictCapacity: 1, EvictRemoved: 0, EvictExpired: 1}, st.Evictions)
+	s.Equal(uint64(2), st.Evicted())
+	s.Equal(1, st.Size)
+	s.InDelta(0.75, st.HitRatio(), 1e-9)
+}
+
+// TestReset 测试清零后元素数量保持不变
+func (s *StatsTestSuite) TestReset() {
+	var c StatsCounter
+	c.Insert()
+	c.Insert()
+	c.Hit()
+	c.Evict(EvictRemoved)
+
+	c.Reset()
+	st := c.Snapshot()
+	s.Zero(st.Hits)
+	s.Zero(st.Insertions)
+	s.Zero(st.Evicted())
+	s.Equal(1, st.Size)
+	s.Zero(st.HitRatio())
+}
+
+type fixedStats Stats
+
+func (f fixedStats) Stats() Stats {
+	return Stats(f)
+}
+
+// TestStatsVar 测试以JSON形式输出统计信息
+func (s *StatsTestSuite) TestStatsVar() {
+	var c StatsCounter
+	c.Hit()
+	c.Miss()
+	c.Insert()
+	c.Insert()
+	c.Evict(EvictCapacity)
+
+	// expvar 不允许重复发布同名变量，使用 -count 重复运行时只发布一次
+	if expvar.Get("cache_stats_test") == nil {
+		Publish("cache_stats_test", fixedStats(c.Snapshot()))
+	}
+	v := expvar.Get("cache_stats_test")
+	s.NotNil(v)
+
+	var got struct {
+		Hits      uint64            `json:"hits"`
+		Misses    uint64            `json:"misses"`
+		Evictions map[string]uint64 `json:"evictions"`
+		Size      int               `json:"size"`
+		HitRatio  float64           `json:"hit_ratio"`
+	}
+	s.NoError(json.Unmarshal([]byte(v.String()), &got))
+	s.Equal(uint64(1), got.Hits)
+	s.Equal(uint64(1), got.Misses)
+	s.Equal(map[string]uint64{"capacity": 1, "removed": 0, "expired": 0}, got.Evictions)
+	s.Equal(1, got.Size)
+	s.InDelta(0.5, got.HitRatio, 1e-9)
+}
+
+// TestStats 运行所有统计计数器测试
+func TestStats(t *testing.T) {
+	suite.Run(t, new(StatsTestSuite))
+}
@@ -0,0 +1,139 @@
+// Package clock 提供可注入的时钟抽象
+//
+// 依赖时间的组件（如带过期时间的缓存）通过 Clock 获取当前时间和定时通知，
+// 生产环境使用 Real，测试中使用 Manual 手动推进时间，从而避免真实的等待。
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时钟接口
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+	// After 在经过d之后向返回的通道发送当时的时间
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Real 基于系统时间的时钟
+var Real Clock = realClock{}
+
+// Sleep 阻塞直到时钟c经过d
+func Sleep(c Clock, d time.Duration) {
+	<-c.After(d)
+}
+
+// waiter 是等待Manual时钟到达某一时刻的通知
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// Manual 手动推进的时钟，只有调用 Advance 或 Set 时时间才会前进
+type Manual struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+	changed *sync.Cond // 等待者增加时广播，供 BlockUntil 使用
+}
+
+// NewManual 创建一个当前时间为now的手动时钟
+func NewManual(now time.Time) *Manual {
+	return &Manual{now: now}
+}
+
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.now
+}
+
+func (m *Manual) After(d time.Duration) <-chan time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	at := m.now.Add(d)
+	if d <= 0 {
+		ch <- m.now
+		return ch
+	}
+
+	m.waiters = append(m.waiters, waiter{at: at, ch: ch})
+	m.cond().Broadcast()
+	return ch
+}
+
+// Waiters 返回尚未到期的等待者数量
+func (m *Manual) Waiters() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.waiters)
+}
+
+// BlockUntil 阻塞直到至少有n个尚未到期的等待者
+//
+// 测试中可以用它确认其他协程已经开始等待，再推进时间。
+func (m *Manual) BlockUntil(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for len(m.waiters) < n {
+		m.cond().Wait()
+	}
+}
+
+func (m *Manual) cond() *sync.Cond {
+	if m.changed == nil {
+		m.changed = sync.NewCond(&m.mu)
+	}
+
+	return m.changed
+}
+
+// Advance 将时钟向前推进d，并唤醒所有已到期的等待者
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(m.now.Add(d))
+}
+
+// Set 将时钟设置为t，并唤醒所有已到期的等待者
+func (m *Manual) Set(t time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(t)
+}
+
+func (m *Manual) set(t time.Time) {
+	m.now = t
+
+	pending := m.waiters[:0]
+	for _, w := range m.waiters {
+		if w.at.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- t
+	}
+
+	// 清空尾部，避免已唤醒的通道被底层数组引用
+	clear(m.waiters[len(pending):])
+	m.waiters = pending
+}
@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ManualTestSuite 是手动时钟的测试套件
+type ManualTestSuite struct {
+	suite.Suite
+	start time.Time
+	clock *Manual
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *ManualTestSuite) SetupTest() {
+	s.start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.clock = NewManual(s.start)
+}
+
+// TestAdvance 测试推进时间
+func (s *ManualTestSuite) TestAdvance() {
+	s.Equal(s.start, s.clock.Now())
+
+	s.clock.Advance(time.Second)
+	s.Equal(s.start.Add(time.Second), s.clock.Now())
+
+	s.clock.Set(s.start)
+	s.Equal(s.start, s.clock.Now())
+}
+
+// TestAfter 测试到期后才会收到通知
+func (s *ManualTestSuite) TestAfter() {
+	ch := s.clock.After(time.Second)
+
+	s.clock.Advance(999 * time.Millisecond)
+	select {
+	case <-ch:
+		s.Fail("未到期不应该收到通知")
+	default:
+	}
+
+	s.clock.Advance(time.Millisecond)
+	select {
+	case t := <-ch:
+		s.Equal(s.start.Add(time.Second), t)
+	default:
+		s.Fail("到期后应该收到通知")
+	}
+	s.Empty(s.clock.waiters)
+}
+
+// TestAfterNonPositive 测试非正的时长立即触发
+func (s *ManualTestSuite) TestAfterNonPositive() {
+	select {
+	case t := <-s.clock.After(0):
+		s.Equal(s.start, t)
+	default:
+		s.Fail("时长为0时应该立即收到通知")
+	}
+}
+
+// TestAfterOrder 测试多个等待者只唤醒已到期的
+func (s *ManualTestSuite) TestAfterOrder() {
+	short := s.clock.After(time.Second)
+	long := s.clock.After(time.Minute)
+
+	s.clock.Advance(2 * time.Second)
+	s.Len(short, 1)
+	s.Len(long, 0)
+	s.Len(s.clock.waiters, 1)
+}
+
+// TestBlockUntil 测试等待其他协程开始等待后再推进时间
+func (s *ManualTestSuite) TestBlockUntil() {
+	done := make(chan struct{})
+	go func() {
+		Sleep(s.clock, time.Second)
+		close(done)
+	}()
+
+	s.clock.BlockUntil(1)
+	s.Equal(1, s.clock.Waiters())
+
+	s.clock.Advance(time.Second)
+	<-done
+	s.Equal(0, s.clock.Waiters())
+
+	// 已有足够的等待者时立即返回
+	s.clock.BlockUntil(0)
+}
+
+// TestReal 测试系统时钟
+func (s *ManualTestSuite) TestReal() {
+	before := time.Now()
+	s.False(Real.Now().Before(before))
+	<-Real.After(time.Millisecond)
+}
+
+// TestManual 运行所有时钟测试
+func TestManual(t *testing.T) {
+	suite.Run(t, new(ManualTestSuite))
+}
@@ -0,0 +1,155 @@
+// Command cachesim 在访问序列上回放多种缓存淘汰策略，比较它们的命中率
+//
+// 访问序列可以来自文件（每行一个键，或 key,size,timestamp 格式的CSV），
+// 也可以由内置的 Zipf、scan、loop 生成器合成。每种策略在每个容量下都会
+// 从空缓存开始回放一遍，输出命中率、字节命中率和淘汰数量。
+//
+// 除 wlru 外，所有策略的容量都按元素个数计算，不考虑元素大小，它们的字节命中率
+// 只作为参考。wlru 是按元素大小限制总字节数的LRU，字节上限为容量乘以访问序列中
+// 不同键的平均大小，在元素大小不一的序列上用于比较字节命中率。
+//
+// 用法示例：
+//
+//	cachesim -gen zipf -n 1000000 -keys 100000 -capacity 1000,10000
+//	cachesim -trace access.csv -policy lru,s3fifo -capacity 500 -output csv
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func main() {
+	if err := cli(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "cachesim:", err)
+		os.Exit(2)
+	}
+}
+
+// cli 解析命令行参数并把结果写入out
+func cli(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("cachesim", flag.ContinueOnError)
+	traceFile := fs.String("trace", "", "访问序列文件，为空时使用合成序列")
+	format := fs.String("format", formatAuto, "访问序列文件格式：auto、keys 或 csv")
+	policyNames := fs.String("policy", "all", "逗号分隔的策略名称，all表示所有策略")
+	capacityList := fs.String("capacity", "100,1000,10000", "逗号分隔的缓存容量（元素个数）")
+	output := fs.String("output", "table", "输出格式：table 或 csv")
+
+	cfg := genConfig{}
+	fs.StringVar(&cfg.kind, "gen", genZipf, "合成序列类型：zipf、scan 或 loop")
+	fs.IntVar(&cfg.n, "n", 1000000, "合成序列的访问次数")
+	fs.Uint64Var(&cfg.keys, "keys", 100000, "合成序列的键空间大小")
+	fs.Float64Var(&cfg.skew, "skew", 1.1, "Zipf分布的参数s，必须大于1")
+	fs.IntVar(&cfg.scan, "scan", 10000, "scan序列中每段Zipf访问和扫描的长度")
+	fs.Int64Var(&cfg.maxSize, "maxsize", 1, "合成序列中元素大小的上限")
+	fs.Uint64Var(&cfg.seed, "seed", 1, "合成序列的随机种子")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	selected, err := selectPolicies(*policyNames)
+	if err != nil {
+		return err
+	}
+
+	capacities, err := parseCapacities(*capacityList)
+	if err != nil {
+		return err
+	}
+
+	trace, err := loadTrace(*traceFile, *format, cfg)
+	if err != nil {
+		return err
+	}
+
+	results, err := run(selected, capacities, trace)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "table":
+		return writeTable(out, results)
+	case "csv":
+		return writeCSV(out, results)
+	default:
+		return fmt.Errorf("unknown output format %q", *output)
+	}
+}
+
+// loadTrace 读取访问序列文件，path为空时按cfg生成合成序列
+func loadTrace(path, format string, cfg genConfig) ([]request, error) {
+	if path == "" {
+		return generate(cfg)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return readTrace(f, format)
+}
+
+// parseCapacities 解析逗号分隔的容量列表
+func parseCapacities(s string) ([]int, error) {
+	var capacities []int
+	for _, field := range strings.Split(s, ",") {
+		capacity, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || capacity <= 0 {
+			return nil, fmt.Errorf("invalid capacity %q", field)
+		}
+		capacities = append(capacities, capacity)
+	}
+
+	if len(capacities) == 0 {
+		return nil, errors.New("no capacity given")
+	}
+
+	return capacities, nil
+}
+
+var header = []string{"policy", "capacity", "requests", "hit_ratio", "byte_hit_ratio", "evictions"}
+
+// fields 返回一条结果在输出中的各列
+func (r result) fields() []string {
+	return []string{
+		r.policy,
+		strconv.Itoa(r.capacity),
+		strconv.Itoa(r.requests),
+		strconv.FormatFloat(r.HitRatio(), 'f', 4, 64),
+		strconv.FormatFloat(r.ByteHitRatio(), 'f', 4, 64),
+		strconv.Itoa(r.evictions),
+	}
+}
+
+// writeTable 以对齐的表格输出结果
+func writeTable(w io.Writer, results []result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+	for _, r := range results {
+		fmt.Fprintln(tw, strings.Join(r.fields(), "\t")+"\t")
+	}
+
+	return tw.Flush()
+}
+
+// writeCSV 以带表头的CSV输出结果
+func writeCSV(w io.Writer, results []result) error {
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, r := range results {
+		cw.Write(r.fields())
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"algorithm/arc"
+	"algorithm/cache"
+	"algorithm/lfu"
+	"algorithm/lru"
+	"algorithm/s3fifo"
+	"algorithm/sieve"
+	"algorithm/slru"
+	"algorithm/tinylfu"
+	"algorithm/twoq"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// factory 根据构造选项创建一个缓存
+type factory func(opts ...cache.Option) cache.Cache[string, int64]
+
+// policy 是一种可以参与模拟的缓存淘汰策略
+type policy struct {
+	name string
+	new  factory
+	// weighted 为true时缓存按元素大小限制总字节数，cache.WithCapacity 传入的是
+	// 字节上限：容量乘以访问序列中不同键的平均大小，与按个数计算的策略大致可比
+	weighted bool
+}
+
+// policies 是所有可用的策略，按输出顺序排列
+var policies = []policy{
+	{name: "lru", new: func(opts ...cache.Option) cache.Cache[string, int64] { return lru.New[string, int64](opts...) }},
+	{name: "wlru", new: newWeightedLRU, weighted: true},
+	{name: "lfu", new: func(opts ...cache.Option) cache.Cache[string, int64] { return lfu.New[string, int64](opts...) }},
+	{name: "arc", new: func(opts ...cache.Option) cache.Cache[string, int64] { return arc.New[string, int64](opts...) }},
+	{name: "tinylfu", new: func(opts ...cache.Option) cache.Cache[string, int64] { return tinylfu.New[string, int64](opts...) }},
+	{name: "2q", new: func(opts ...cache.Option) cache.Cache[string, int64] { return twoq.New[string, int64](opts...) }},
+	{name: "slru", new: func(opts ...cache.Option) cache.Cache[string, int64] { return slru.New[string, int64](opts...) }},
+	{name: "sieve", new: func(opts ...cache.Option) cache.Cache[string, int64] { return sieve.New[string, int64](opts...) }},
+	{name: "s3fifo", new: func(opts ...cache.Option) cache.Cache[string, int64] { return s3fifo.New[string, int64](opts...) }},
+}
+
+// newWeightedLRU 创建以值（元素大小）为开销、以容量选项为字节上限的LRU
+func newWeightedLRU(opts ...cache.Option) cache.Cache[string, int64] {
+	maxCost := int64(cache.NewOptions(opts...).Capacity)
+	return lru.NewWeightedLRU(maxCost, func(_ string, size int64) int64 { return size }, opts...)
+}
+
+// selectPolicies 按逗号分隔的名称选择策略，"all"表示所有策略
+func selectPolicies(names string) ([]policy, error) {
+	if names == "all" {
+		return policies, nil
+	}
+
+	var selected []policy
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, p := range policies {
+			if p.name == name {
+				selected = append(selected, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown policy %q", name)
+		}
+	}
+
+	return selected, nil
+}
+
+// result 是一次模拟的结果
+type result struct {
+	policy    string
+	capacity  int
+	requests  int
+	hits      int
+	bytes     int64 // 所有访问的字节数
+	hitBytes  int64 // 命中访问的字节数
+	evictions int   // 因容量不足淘汰的元素数量
+}
+
+// HitRatio 返回命中率，没有访问时返回0
+func (r result) HitRatio() float64 {
+	if r.requests == 0 {
+		return 0
+	}
+
+	return float64(r.hits) / float64(r.requests)
+}
+
+// ByteHitRatio 返回字节命中率，没有访问时返回0
+func (r result) ByteHitRatio() float64 {
+	if r.bytes == 0 {
+		return 0
+	}
+
+	return float64(r.hitBytes) / float64(r.bytes)
+}
+
+// simulate 在容量为capacity的缓存上回放访问序列
+//
+// 每次访问先 Get，未命中时再 Put，值为元素大小。除 weighted 策略外容量按元素
+// 个数计算，缓存不考虑元素大小，字节命中率只作为参考。
+func simulate(p policy, capacity int, trace []request) (result, error) {
+	res := result{policy: p.name, capacity: capacity, requests: len(trace)}
+	limit := capacity
+	if p.weighted {
+		var err error
+		if limit, err = byteLimit(capacity, meanSize(trace)); err != nil {
+			return result{}, err
+		}
+	}
+	c := p.new(
+		cache.WithCapacity(limit),
+		cache.WithOnEvict(func(_ string, _ int64, reason cache.EvictReason) {
+			if reason == cache.EvictCapacity {
+				res.evictions++
+			}
+		}),
+	)
+
+	for _, req := range trace {
+		res.bytes += req.size
+		if _, ok := c.Get(req.key); ok {
+			res.hits++
+			res.hitBytes += req.size
+			continue
+		}
+		c.Put(req.key, req.size)
+	}
+
+	return res, nil
+}
+
+// byteLimit 返回按字节限制的策略在容量为capacity时的字节上限，即capacity乘以平均大小后四舍五入
+func byteLimit(capacity int, mean float64) (int, error) {
+	limit := math.Round(float64(capacity) * mean)
+	if limit < 0 || limit >= math.MaxInt {
+		return 0, fmt.Errorf("byte limit for capacity %d and mean size %g is out of range", capacity, mean)
+	}
+
+	return int(limit), nil
+}
+
+// meanSize 返回访问序列中不同键的平均大小，序列为空时返回1
+func meanSize(trace []request) float64 {
+	sizes := make(map[string]int64)
+	for _, req := range trace {
+		sizes[req.key] = req.size
+	}
+
+	var total int64
+	for _, size := range sizes {
+		total += size
+	}
+	if len(sizes) == 0 {
+		return 1
+	}
+
+	return float64(total) / float64(len(sizes))
+}
+
+// run 在所有策略和容量的组合上回放访问序列，结果按策略、容量的顺序排列
+func run(selected []policy, capacities []int, trace []request) ([]result, error) {
+	results := make([]result, 0, len(selected)*len(capacities))
+	for _, p := range selected {
+		for _, capacity := range capacities {
+			res, err := simulate(p, capacity, trace)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, res)
+		}
+	}
+
+	return results, nil
+}
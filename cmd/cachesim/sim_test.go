@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SimTestSuite 是缓存模拟的测试套件
+type SimTestSuite struct {
+	suite.Suite
+}
+
+// TestSimulate 测试命中率、字节命中率和淘汰数量的统计
+func (s *SimTestSuite) TestSimulate() {
+	lru, err := selectPolicies("lru")
+	s.NoError(err)
+
+	trace := []request{
+		{key: "a", size: 10},
+		{key: "b", size: 30},
+		{key: "a", size: 10}, // 命中
+		{key: "c", size: 50}, // 淘汰b
+		{key: "b", size: 30}, // 未命中，淘汰a
+		{key: "c", size: 50}, // 命中
+	}
+	res, err := simulate(lru[0], 2, trace)
+	s.NoError(err)
+
+	s.Equal(6, res.requests)
+	s.Equal(2, res.hits)
+	s.Equal(2, res.evictions)
+	s.InDelta(2.0/6, res.HitRatio(), 1e-9)
+	s.InDelta(60.0/180, res.ByteHitRatio(), 1e-9)
+	s.Zero(result{}.HitRatio())
+	s.Zero(result{}.ByteHitRatio())
+}
+
+// TestWeighted 测试按字节限制的 wlru 考虑元素大小，放不下的大元素不会挤掉小元素
+func (s *SimTestSuite) TestWeighted() {
+	selected, err := selectPolicies("lru,wlru")
+	s.NoError(err)
+
+	// 平均大小为30，容量2对应60字节
+	trace := []request{
+		{key: "a", size: 10},
+		{key: "b", size: 10},
+		{key: "c", size: 70},
+		{key: "a", size: 10},
+		{key: "b", size: 10},
+		{key: "c", size: 70},
+	}
+	s.Equal(30.0, meanSize(trace))
+	results, err := run(selected, []int{2}, trace)
+	s.NoError(err)
+
+	s.Zero(results[0].hits)
+	s.Equal(2, results[1].hits)
+	s.InDelta(20.0/180, results[1].ByteHitRatio(), 1e-9)
+	s.Equal(1.0, meanSize(nil))
+}
+
+// TestByteLimit 测试字节上限四舍五入，超出范围时返回错误
+func (s *SimTestSuite) TestByteLimit() {
+	limit, err := byteLimit(3, 2.5)
+	s.NoError(err)
+	s.Equal(8, limit)
+
+	limit, err = byteLimit(1000, 1.0004)
+	s.NoError(err)
+	s.Equal(1000, limit)
+
+	_, err = byteLimit(math.MaxInt/2, 4)
+	s.Error(err)
+
+	// 平均大小不是整数时按实际平均值计算，而不是截断
+	selected, err := selectPolicies("wlru")
+	s.NoError(err)
+	trace := []request{{key: "a", size: 1}, {key: "b", size: 2}}
+	s.Equal(1.5, meanSize(trace))
+	results, err := run(selected, []int{2}, trace)
+	s.NoError(err)
+	s.Zero(results[0].evictions)
+}
+
+// TestLoop 测试循环访问超过容量时LRU完全失效，而带准入策略的 W-TinyLFU 仍能命中
+func (s *SimTestSuite) TestLoop() {
+	trace, err := generate(genConfig{kind: genLoop, n: 10000, keys: 200})
+	s.NoError(err)
+
+	selected, err := selectPolicies("lru,tinylfu")
+	s.NoError(err)
+	results, err := run(selected, []int{100}, trace)
+	s.NoError(err)
+
+	s.Zero(results[0].HitRatio())
+	s.Greater(results[1].HitRatio(), 0.0)
+}
+
+// TestAllPolicies 测试所有策略都能完成模拟，容量越大命中率不降低
+func (s *SimTestSuite) TestAllPolicies() {
+	trace, err := generate(genConfig{kind: genZipf, n: 20000, keys: 5000, skew: 1.1, seed: 3})
+	s.NoError(err)
+
+	results, err := run(policies, []int{50, 500}, trace)
+	s.NoError(err)
+	s.Len(results, 2*len(policies))
+	for i := 0; i < len(results); i += 2 {
+		small, large := results[i], results[i+1]
+		s.Equal(small.policy, large.policy)
+		s.Greater(small.HitRatio(), 0.0, small.policy)
+		s.GreaterOrEqual(large.HitRatio(), small.HitRatio(), small.policy)
+		s.Greater(small.evictions, large.evictions, small.policy)
+	}
+}
+
+// TestSelectPolicies 测试按名称选择策略
+func (s *SimTestSuite) TestSelectPolicies() {
+	selected, err := selectPolicies("s3fifo, 2q")
+	s.NoError(err)
+	s.Len(selected, 2)
+	s.Equal("s3fifo", selected[0].name)
+	s.Equal("2q", selected[1].name)
+
+	selected, err = selectPolicies("all")
+	s.NoError(err)
+	s.Len(selected, len(policies))
+
+	_, err = selectPolicies("lru,mru")
+	s.Error(err)
+}
+
+// TestCLI 测试命令行参数和两种输出格式
+func (s *SimTestSuite) TestCLI() {
+	var out bytes.Buffer
+	err := cli([]string{"-gen", "loop", "-n", "100", "-keys", "10", "-policy", "lru,lfu", "-capacity", "5,10", "-output", "csv"}, &out)
+	s.NoError(err)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	s.NoError(err)
+	s.Equal(header, records[0])
+	s.Equal([]string{"lru", "5", "100", "0.0000", "0.0000", "95"}, records[1])
+	s.Equal([]string{"lru", "10", "100", "0.9000", "0.9000", "0"}, records[2])
+	s.Len(records, 5)
+
+	out.Reset()
+	err = cli([]string{"-gen", "loop", "-n", "100", "-keys", "10", "-policy", "lru", "-capacity", "10"}, &out)
+	s.NoError(err)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	s.Len(lines, 2)
+	s.Equal(strings.Fields(lines[1]), []string{"lru", "10", "100", "0.9000", "0.9000", "0"})
+
+	for _, args := range [][]string{
+		{"-capacity", "0"},
+		{"-capacity", "x"},
+		{"-policy", "mru"},
+		{"-output", "xml", "-n", "10"},
+		{"-trace", "/nonexistent/trace"},
+	} {
+		s.Error(cli(args, &out), args)
+	}
+}
+
+// TestSim 运行所有缓存模拟测试
+func TestSim(t *testing.T) {
+	suite.Run(t, new(SimTestSuite))
+}
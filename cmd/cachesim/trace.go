@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+// request 是访问序列中的一次访问
+type request struct {
+	key  string
+	size int64 // 元素大小（字节），用于计算字节命中率
+	time int64 // 访问时间戳，键值序列中为访问的序号
+}
+
+// 访问序列文件的格式
+const (
+	formatAuto = "auto" // 根据第一行是否包含逗号判断
+	formatKeys = "keys" // 每行一个键，大小均为1
+	formatCSV  = "csv"  // 每行为 key,size,timestamp，允许有表头
+)
+
+// readTrace 按format读取访问序列
+func readTrace(r io.Reader, format string) ([]request, error) {
+	br := bufio.NewReader(r)
+	if format == formatAuto {
+		format = detectFormat(br)
+	}
+
+	switch format {
+	case formatKeys:
+		return readKeys(br)
+	case formatCSV:
+		return readCSV(br)
+	default:
+		return nil, fmt.Errorf("unknown trace format %q", format)
+	}
+}
+
+// detectFormat 查看第一行，包含逗号时视为CSV
+func detectFormat(br *bufio.Reader) string {
+	line, _ := br.Peek(br.Size())
+	if i := strings.IndexByte(string(line), '\n'); i >= 0 {
+		line = line[:i]
+	}
+	if strings.Contains(string(line), ",") {
+		return formatCSV
+	}
+
+	return formatKeys
+}
+
+// readKeys 读取每行一个键的访问序列，忽略空行
+func readKeys(r io.Reader) ([]request, error) {
+	var trace []request
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" {
+			continue
+		}
+		trace = append(trace, request{key: key, size: 1, time: int64(len(trace))})
+	}
+
+	return trace, scanner.Err()
+}
+
+// readCSV 读取 key,size,timestamp 格式的访问序列
+//
+// 第一行的大小不是数字时视为表头并跳过；大小必须为正数。
+func readCSV(r io.Reader) ([]request, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	var trace []request
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return trace, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.ParseInt(record[1], 10, 64)
+		if err != nil && line == 1 {
+			continue
+		}
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("line %d: invalid size %q", line, record[1])
+		}
+
+		time, err := strconv.ParseInt(record[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp %q", line, record[2])
+		}
+
+		trace = append(trace, request{key: record[0], size: size, time: time})
+	}
+}
+
+// 合成访问序列的类型
+const (
+	genZipf = "zipf" // 键服从Zipf分布
+	genScan = "scan" // Zipf访问中穿插只访问一次的顺序扫描
+	genLoop = "loop" // 按顺序循环访问所有键
+)
+
+// genConfig 是合成访问序列的参数
+type genConfig struct {
+	kind    string
+	n       int     // 访问次数
+	keys    uint64  // 键空间大小
+	skew    float64 // Zipf分布的参数s，必须大于1
+	scan    int     // scan中每段Zipf访问和每段扫描的长度
+	maxSize int64   // 元素大小的上限，每个键的大小在 [1, maxSize] 内固定
+	seed    uint64
+}
+
+// generate 按cfg生成合成访问序列，相同的参数总是生成相同的序列
+func generate(cfg genConfig) ([]request, error) {
+	if cfg.n < 0 || cfg.keys == 0 {
+		return nil, errors.New("trace length must be non-negative and key space positive")
+	}
+	if cfg.maxSize <= 0 {
+		cfg.maxSize = 1
+	}
+
+	var next func(i int) string
+	switch cfg.kind {
+	case genZipf, genScan:
+		if cfg.skew <= 1 {
+			return nil, fmt.Errorf("zipf skew must be greater than 1, got %g", cfg.skew)
+		}
+		r := rand.New(rand.NewPCG(cfg.seed, cfg.seed))
+		z := rand.NewZipf(r, cfg.skew, 1, cfg.keys-1)
+		next = func(int) string { return strconv.FormatUint(z.Uint64(), 10) }
+
+		if cfg.kind == genScan {
+			if cfg.scan <= 0 {
+				return nil, errors.New("scan length must be positive")
+			}
+			// 扫描使用独立的键空间，每个键只出现一次
+			scanned := 0
+			zipf := next
+			next = func(i int) string {
+				if i/cfg.scan%2 == 0 {
+					return zipf(i)
+				}
+				scanned++
+				return "scan-" + strconv.Itoa(scanned)
+			}
+		}
+
+	case genLoop:
+		next = func(i int) string { return strconv.FormatUint(uint64(i)%cfg.keys, 10) }
+
+	default:
+		return nil, fmt.Errorf("unknown generator %q", cfg.kind)
+	}
+
+	trace := make([]request, cfg.n)
+	for i := range trace {
+		key := next(i)
+		trace[i] = request{key: key, size: sizeOf(key, cfg.maxSize), time: int64(i)}
+	}
+
+	return trace, nil
+}
+
+// sizeOf 根据键的哈希确定元素大小，同一个键的大小始终相同
+func sizeOf(key string, maxSize int64) int64 {
+	if maxSize <= 1 {
+		return 1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64()%uint64(maxSize)) + 1
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TraceTestSuite 是访问序列读取和生成的测试套件
+type TraceTestSuite struct {
+	suite.Suite
+}
+
+// TestReadKeys 测试读取每行一个键的访问序列
+func (s *TraceTestSuite) TestReadKeys() {
+	trace, err := readTrace(strings.NewReader("a\n b \n\nc\n"), formatAuto)
+	s.NoError(err)
+	s.Equal([]request{
+		{key: "a", size: 1, time: 0},
+		{key: "b", size: 1, time: 1},
+		{key: "c", size: 1, time: 2},
+	}, trace)
+}
+
+// TestReadCSV 测试读取带表头的CSV访问序列
+func (s *TraceTestSuite) TestReadCSV() {
+	in := "key,size,timestamp\na,100,10\nb, 20, 11\n"
+	trace, err := readTrace(strings.NewReader(in), formatAuto)
+	s.NoError(err)
+	s.Equal([]request{
+		{key: "a", size: 100, time: 10},
+		{key: "b", size: 20, time: 11},
+	}, trace)
+
+	// 没有表头时第一行也是数据
+	trace, err = readTrace(strings.NewReader("a,1,1\n"), formatCSV)
+	s.NoError(err)
+	s.Len(trace, 1)
+}
+
+// TestReadCSVErrors 测试CSV中无效的大小、时间戳和列数
+func (s *TraceTestSuite) TestReadCSVErrors() {
+	for _, in := range []string{
+		"a,1,1\nb,x,2\n",
+		"a,1,1\nb,0,2\n",
+		"a,1,x\n",
+		"a,1\n",
+	} {
+		_, err := readTrace(strings.NewReader(in), formatCSV)
+		s.Error(err, in)
+	}
+
+	_, err := readTrace(strings.NewReader("a\n"), "xml")
+	s.Error(err)
+}
+
+// TestGenerateDeterministic 测试相同参数生成相同的序列
+func (s *TraceTestSuite) TestGenerateDeterministic() {
+	cfg := genConfig{kind: genZipf, n: 1000, keys: 100, skew: 1.2, maxSize: 64, seed: 7}
+	a, err := generate(cfg)
+	s.NoError(err)
+	b, err := generate(cfg)
+	s.NoError(err)
+	s.Equal(a, b)
+	s.Len(a, 1000)
+
+	// 同一个键的大小始终相同，且在 [1, maxSize] 内
+	sizes := make(map[string]int64)
+	for _, req := range a {
+		s.GreaterOrEqual(req.size, int64(1))
+		s.LessOrEqual(req.size, int64(64))
+		if size, ok := sizes[req.key]; ok {
+			s.Equal(size, req.size)
+		}
+		sizes[req.key] = req.size
+	}
+}
+
+// TestGenerateLoop 测试循环序列按顺序访问所有键
+func (s *TraceTestSuite) TestGenerateLoop() {
+	trace, err := generate(genConfig{kind: genLoop, n: 7, keys: 3})
+	s.NoError(err)
+
+	keys := make([]string, len(trace))
+	for i, req := range trace {
+		keys[i] = req.key
+	}
+	s.Equal([]string{"0", "1", "2", "0", "1", "2", "0"}, keys)
+}
+
+// TestGenerateScan 测试扫描序列交替出现Zipf访问和只出现一次的扫描键
+func (s *TraceTestSuite) TestGenerateScan() {
+	trace, err := generate(genConfig{kind: genScan, n: 40, keys: 100, skew: 1.2, scan: 10, seed: 1})
+	s.NoError(err)
+
+	for i, req := range trace {
+		s.Equal(i/10%2 == 1, strings.HasPrefix(req.key, "scan-"), i)
+	}
+	s.Equal("scan-1", trace[10].key)
+	s.Equal("scan-11", trace[30].key)
+}
+
+// TestGenerateErrors 测试无效的生成参数
+func (s *TraceTestSuite) TestGenerateErrors() {
+	for _, cfg := range []genConfig{
+		{kind: "random", n: 10, keys: 10},
+		{kind: genZipf, n: 10, keys: 10, skew: 1},
+		{kind: genScan, n: 10, keys: 10, skew: 1.2},
+		{kind: genLoop, n: 10, keys: 0},
+	} {
+		_, err := generate(cfg)
+		s.Error(err, cfg.kind)
+	}
+}
+
+// TestTrace 运行所有访问序列测试
+func TestTrace(t *testing.T) {
+	suite.Run(t, new(TraceTestSuite))
+}
@@ -5,7 +5,10 @@
 // 以及在指定节点前后进行插入和删除操作。
 package double_linked_list
 
-import "errors"
+import (
+	"errors"
+	"iter"
+)
 
 // DNode 双向链表节点
 type DNode[T any] struct {
@@ -299,3 +302,85 @@ func (d *DoubleLinkedList[T]) MoveToTail(node *DNode[T]) error {
 
 	return nil
 }
+
+// Front 返回链表的第一个实际节点
+//
+// 返回值：
+//
+//	*DNode[T]: 第一个实际节点，链表为空时返回nil
+func (d *DoubleLinkedList[T]) Front() *DNode[T] {
+	if d.size == 0 {
+		return nil
+	}
+
+	return d.dummpyHead.Next
+}
+
+// Back 返回链表的最后一个实际节点
+//
+// 返回值：
+//
+//	*DNode[T]: 最后一个实际节点，链表为空时返回nil
+func (d *DoubleLinkedList[T]) Back() *DNode[T] {
+	if d.size == 0 {
+		return nil
+	}
+
+	return d.dummpyTail.Pre
+}
+
+// Next 返回指定节点的后一个实际节点
+//
+// 参数：
+//
+//	node: 链表中的节点
+//
+// 返回值：
+//
+//	*DNode[T]: 后一个实际节点，node是最后一个节点或无效时返回nil
+func (d *DoubleLinkedList[T]) Next(node *DNode[T]) *DNode[T] {
+	if node == nil || node == d.dummpyTail || node.Next == d.dummpyTail {
+		return nil
+	}
+
+	return node.Next
+}
+
+// Nodes 返回从头到尾遍历链表节点的迭代器
+//
+// 遍历时会在访问当前节点之前记录其后继节点，因此可以在遍历过程中删除当前节点；
+// 其他修改链表的操作会导致遍历结果不确定。
+//
+// 返回值：
+//
+//	iter.Seq[*DNode[T]]: 链表节点的迭代器
+func (d *DoubleLinkedList[T]) Nodes() iter.Seq[*DNode[T]] {
+	return func(yield func(*DNode[T]) bool) {
+		for node := d.dummpyHead.Next; node != d.dummpyTail; {
+			next := node.Next
+			if !yield(node) {
+				return
+			}
+			node = next
+		}
+	}
+}
+
+// Backward 返回从尾到头遍历链表节点的迭代器
+//
+// 与 Nodes 相同，遍历过程中可以删除当前节点。
+//
+// 返回值：
+//
+//	iter.Seq[*DNode[T]]: 链表节点的迭代器
+func (d *DoubleLinkedList[T]) Backward() iter.Seq[*DNode[T]] {
+	return func(yield func(*DNode[T]) bool) {
+		for node := d.dummpyTail.Pre; node != d.dummpyHead; {
+			pre := node.Pre
+			if !yield(node) {
+				return
+			}
+			node = pre
+		}
+	}
+}
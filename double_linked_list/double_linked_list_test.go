@@ -310,6 +310,99 @@ func (suite *DoubleLinkedListTestSuite) TestComplexOperations() {
 	suite.Equal(2, current.Val)
 }
 
+// TestFrontAndNext 测试获取第一个节点和后继节点
+func (suite *DoubleLinkedListTestSuite) TestFrontAndNext() {
+	suite.Nil(suite.list.Front())
+
+	suite.list.Append(1)
+	suite.list.Append(2)
+
+	front := suite.list.Front()
+	suite.Equal(1, front.Val)
+
+	next := suite.list.Next(front)
+	suite.Equal(2, next.Val)
+
+	// 最后一个节点没有后继
+	suite.Nil(suite.list.Next(next))
+	suite.Nil(suite.list.Next(nil))
+}
+
+// TestBack 测试获取最后一个节点
+func (suite *DoubleLinkedListTestSuite) TestBack() {
+	suite.Nil(suite.list.Back())
+
+	suite.list.Append(1)
+	suite.Equal(1, suite.list.Back().Val)
+
+	suite.list.Append(2)
+	suite.Equal(2, suite.list.Back().Val)
+
+	suite.list.RemoveTail()
+	suite.Equal(1, suite.list.Back().Val)
+}
+
+// TestNodes 测试遍历链表节点
+func (suite *DoubleLinkedListTestSuite) TestNodes() {
+	// 空链表不产生任何节点
+	for range suite.list.Nodes() {
+		suite.Fail("空链表不应该有节点")
+	}
+
+	suite.list.Append(1)
+	suite.list.Append(2)
+	suite.list.Append(3)
+
+	var vals []int
+	for node := range suite.list.Nodes() {
+		vals = append(vals, node.Val)
+	}
+	suite.Equal([]int{1, 2, 3}, vals)
+
+	// 提前结束遍历
+	vals = vals[:0]
+	for node := range suite.list.Nodes() {
+		vals = append(vals, node.Val)
+		break
+	}
+	suite.Equal([]int{1}, vals)
+
+	// 遍历时删除当前节点
+	for node := range suite.list.Nodes() {
+		if node.Val%2 == 1 {
+			suite.NoError(suite.list.Remove(node))
+		}
+	}
+	suite.Equal(1, suite.list.Size())
+	suite.Equal(2, suite.list.dummpyHead.Next.Val)
+}
+
+// TestBackward 测试从尾到头遍历链表节点
+func (suite *DoubleLinkedListTestSuite) TestBackward() {
+	for range suite.list.Backward() {
+		suite.Fail("空链表不应该有节点")
+	}
+
+	suite.list.Append(1)
+	suite.list.Append(2)
+	suite.list.Append(3)
+
+	var vals []int
+	for node := range suite.list.Backward() {
+		vals = append(vals, node.Val)
+	}
+	suite.Equal([]int{3, 2, 1}, vals)
+
+	// 遍历时删除当前节点
+	for node := range suite.list.Backward() {
+		if node.Val != 1 {
+			suite.NoError(suite.list.Remove(node))
+		}
+	}
+	suite.Equal(1, suite.list.Size())
+	suite.Equal(1, suite.list.dummpyTail.Pre.Val)
+}
+
 // 运行测试套件
 func TestDoubleLinkedList(t *testing.T) {
 	suite.Run(t, new(DoubleLinkedListTestSuite))
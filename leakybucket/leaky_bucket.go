@@ -1,48 +1,214 @@
+// Package leakybucket 实现了漏桶限流器
+//
+// 每个请求向桶中注入一份水，桶以固定速率漏水，水位不能超过容量。水位使用
+// “水量×1000”的定点数表示，避免按纳秒漏水时丢失精度。桶的状态（水位和更新
+// 时间）保存在一个不可变的快照中，通过原子指针和CAS循环整体替换，无需加锁。
+//
+// 当前时间和等待都通过 clock.Clock 获取，测试中用 WithClock 注入 clock.Manual
+// 即可在不真实等待的情况下验证所有行为。
 package leakybucket
 
 import (
+	"algorithm/clock"
+	"algorithm/ratelimit"
+	"context"
+	"fmt"
 	"sync/atomic"
 	"time"
 )
 
-type leakyBucket struct {
+// unit 是一个请求在定点数水位中对应的水量
+const unit = 1000
+
+// ErrExceedsCapacity 表示请求的数量超过了桶的容量，永远无法满足，与
+// ratelimit.ErrExceedsBurst 相同
+var ErrExceedsCapacity = ratelimit.ErrExceedsBurst
+
+// state 是桶在某一时刻的状态，创建后不再修改
+type state struct {
+	last  int64 // 上次更新水位的时间（Unix纳秒）
+	water int64 // 当前水位（×1000），有未到期的预约时可能超过容量
+}
+
+// LeakyBucket 漏桶限流器，实现了 ratelimit.Limiter
+//
+// 桶的容量对应 Burst，每秒漏出的水量对应 Limit。
+type LeakyBucket struct {
 	capacity int64
-	rate     float64
-	lastTime atomic.Uint64
-	current  atomic.Uint64
+	limit    float64 // 每秒漏出的水量
+	rate     float64 // 每纳秒漏出的水量（×1000）
+	clock    clock.Clock
+	state    atomic.Pointer[state]
+}
+
+// Option 是漏桶的构造选项
+type Option func(l *LeakyBucket)
+
+// WithClock 指定漏桶使用的时钟，默认使用 clock.Real
+func WithClock(c clock.Clock) Option {
+	return func(l *LeakyBucket) {
+		l.clock = c
+	}
 }
 
-func New(capacity int64, rate float64) *leakyBucket {
-	return &leakyBucket{
-		lastTime: atomic.Uint64{},
-		current:  atomic.Uint64{},
+// New 创建容量为capacity、每秒漏出rate份水的漏桶
+func New(capacity int64, rate float64, opts ...Option) *LeakyBucket {
+	l := &LeakyBucket{
 		capacity: capacity,
-		rate:     rate / 1000,
+		limit:    rate,
+		rate:     rate * unit / float64(time.Second),
+		clock:    clock.Real,
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
+	l.state.Store(&state{})
+
+	return l
 }
 
-func (l *leakyBucket) Allow() bool {
-	now := time.Now().UnixNano() / 1e6
+var _ ratelimit.Limiter = (*LeakyBucket)(nil)
 
-	for {
-		lastTime := l.lastTime.Load()
-		oldWater := l.current.Load()
-		var leakedWater int64
-		if now > int64(lastTime) {
-			leakedWater = int64(float64(now-int64(lastTime)) * l.rate)
-		}
+// Limit 返回每秒漏出的水量，即长期平均每秒允许的请求数
+func (l *LeakyBucket) Limit() float64 {
+	return l.limit
+}
+
+// Burst 返回桶的容量，即一次最多可以通过的请求数
+func (l *LeakyBucket) Burst() int {
+	return int(l.capacity)
+}
+
+// Allow 判断当前是否可以通过一个请求
+func (l *LeakyBucket) Allow() bool {
+	return l.AllowN(1)
+}
 
-		newWater := max(int64(oldWater)-leakedWater, 0)
+// AllowN 判断当前是否可以通过n个请求，可以时注入n份水
+func (l *LeakyBucket) AllowN(n int) bool {
+	return l.allowN(l.clock.Now(), n)
+}
 
-		if newWater >= l.capacity*1000 {
+func (l *LeakyBucket) allowN(now time.Time, n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	for {
+		old := l.state.Load()
+		cur := l.leak(old, now.UnixNano())
+		if cur.water+int64(n)*unit > l.capacity*unit {
 			return false
 		}
 
-		if l.current.CompareAndSwap(oldWater, uint64(newWater+1000)) {
-			l.lastTime.CompareAndSwap(lastTime, uint64(now))
+		cur.water += int64(n) * unit
+		if l.state.CompareAndSwap(old, cur) {
 			return true
 		}
+	}
+}
+
+// Reserve 预约一个请求，等价于 ReserveN(1)
+func (l *LeakyBucket) Reserve() ratelimit.Reservation {
+	return l.ReserveN(1)
+}
+
+// ReserveN 预约n个请求，返回的预约说明需要等待多久才能执行
+//
+// 预约总会立即注入水量，因此水位可能暂时超过容量，后续的请求需要等待更久。
+// n超过容量时预约无效（OK 返回false），桶的状态不变。
+func (l *LeakyBucket) ReserveN(n int) ratelimit.Reservation {
+	now := l.clock.Now()
+	if n <= 0 {
+		return ratelimit.NewReservation(l.clock, 0, now, nil)
+	}
+	if int64(n) > l.capacity {
+		return ratelimit.InvalidReservation()
+	}
+
+	ts := now.UnixNano()
+	for {
+		old := l.state.Load()
+		cur := l.leak(old, ts)
+		cur.water += int64(n) * unit
+		if l.state.CompareAndSwap(old, cur) {
+			return ratelimit.NewReservation(l.clock, n, now.Add(l.delay(cur.water)), l.refund)
+		}
+	}
+}
+
+// Wait 阻塞直到可以通过一个请求，等价于 WaitN(ctx, 1)
+func (l *LeakyBucket) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN 阻塞直到可以通过n个请求，或者ctx被取消
+//
+// n超过容量时返回 ErrExceedsCapacity；ctx的截止时间早于需要等待的时间时
+// 立即返回 ratelimit.ErrDeadline。返回错误时预约被取消，已注入的水量退回桶中。
+func (l *LeakyBucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := l.ReserveN(n)
+	if !r.OK() {
+		return fmt.Errorf("%w: n=%d, capacity=%d", ErrExceedsCapacity, n, l.capacity)
+	}
+
+	return ratelimit.Wait(ctx, l.clock, r)
+}
+
+// leak 返回从s漏水到ts时刻后的新状态
+//
+// 漏出的水量向下取整，更新时间只前进漏出这些水所用的时间，不足一个单位的
+// 部分留到下次累计，因此频繁调用也不会少漏水。并发调用时ts可能早于上次更新
+// 的时间，此时不漏水。
+func (l *LeakyBucket) leak(s *state, ts int64) *state {
+	if ts <= s.last {
+		return &state{last: s.last, water: s.water}
+	}
+
+	leaked := float64(ts-s.last) * l.rate
+	if leaked >= float64(s.water) {
+		return &state{last: ts}
+	}
+
+	units := int64(leaked)
+	if units == 0 {
+		return &state{last: s.last, water: s.water}
+	}
+
+	return &state{
+		last:  s.last + int64(float64(units)/l.rate),
+		water: s.water - units,
+	}
+}
 
+// delay 返回水位从water漏到不超过容量所需的时间
+func (l *LeakyBucket) delay(water int64) time.Duration {
+	excess := water - l.capacity*unit
+	if excess <= 0 {
+		return 0
+	}
+	d := float64(excess) / l.rate
+	if l.rate <= 0 || d >= float64(ratelimit.InfDuration) {
+		return ratelimit.InfDuration
 	}
 
+	// 向上取整，保证等待结束时水位确实已不超过容量
+	return time.Duration(d) + 1
+}
+
+// refund 在now时刻退回n份水，水位最低为0
+func (l *LeakyBucket) refund(now time.Time, n int) {
+	ts := now.UnixNano()
+	for {
+		old := l.state.Load()
+		cur := l.leak(old, ts)
+		cur.water = max(cur.water-int64(n)*unit, 0)
+		if l.state.CompareAndSwap(old, cur) {
+			return
+		}
+	}
 }
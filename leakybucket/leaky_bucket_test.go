@@ -1,15 +1,26 @@
 package leakybucket
 
 import (
+	"algorithm/clock"
+	"algorithm/ratelimit"
+	"algorithm/ratelimit/ratelimittest"
+	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
-	"testing/synctest"
 	"time"
 )
 
+// newTestBucket 创建使用手动时钟的漏桶
+func newTestBucket(capacity int64, rate float64) (*LeakyBucket, *clock.Manual) {
+	clk := clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return New(capacity, rate, WithClock(clk)), clk
+}
+
 func TestLeakyBucket(t *testing.T) {
 	// 创建一个容量为10，每秒漏1个水的漏桶
-	bucket := New(10, 1)
+	bucket, clk := newTestBucket(10, 1)
 
 	// 前10个请求应该都通过
 	for i := 0; i < 10; i++ {
@@ -23,60 +34,301 @@ func TestLeakyBucket(t *testing.T) {
 		t.Error("第11个请求应该被拒绝")
 	}
 
-	// 等待1秒后，应该又能通过一个请求
-	time.Sleep(1 * time.Second)
+	// 1秒后，应该又能通过一个请求
+	clk.Advance(time.Second)
 	if !bucket.Allow() {
 		t.Error("等待1秒后，应该能通过一个请求")
 	}
+	if bucket.Allow() {
+		t.Error("等待1秒后，只能通过一个请求")
+	}
 }
 
 func TestLeakyBucket2(t *testing.T) {
-	synctest.Test(t, func(t *testing.T) {
-		bucket := New(20, 1)
+	bucket, clk := newTestBucket(20, 1)
 
-		for i := 0; i < 20; i++ {
-			if !bucket.Allow() {
-				t.Errorf("第%d个请求应该通过", i+1)
-			}
+	for i := 0; i < 20; i++ {
+		if !bucket.Allow() {
+			t.Errorf("第%d个请求应该通过", i+1)
 		}
+	}
 
-		if bucket.Allow() {
-			t.Error("第21个请求应该被拒绝")
-		}
+	if bucket.Allow() {
+		t.Error("第21个请求应该被拒绝")
+	}
 
-		time.Sleep(1 * time.Second)
-		synctest.Wait()
+	// 不足1秒时漏出的水不够一个请求
+	clk.Advance(999 * time.Millisecond)
+	if bucket.Allow() {
+		t.Error("等待不足1秒，请求应该被拒绝")
+	}
 
-		if !bucket.Allow() {
-			t.Error("等待1秒后，应该能通过一个请求")
-		}
-	})
+	clk.Advance(time.Millisecond)
+	if !bucket.Allow() {
+		t.Error("等待1秒后，应该能通过一个请求")
+	}
 }
 
 func TestLeakyBucket3(t *testing.T) {
-	bucket := New(20, 1)
-
-	synctest.Test(t, func(t *testing.T) {
-		var wg sync.WaitGroup
-		var errCount int
-		for i := 0; i < 22; i++ {
-			wg.Go(func() {
-				if !bucket.Allow() {
-					// t.Errorf("第%d个请求应该通过", i+1)
-					errCount++
-
-				}
-			})
-		}
+	bucket, _ := newTestBucket(20, 1)
 
-		if errCount > 0 {
-			t.Error("不应该有错误的请求")
-		}
-		synctest.Wait()
+	var wg sync.WaitGroup
+	var errCount atomic.Int32
+	for i := 0; i < 22; i++ {
+		wg.Go(func() {
+			if !bucket.Allow() {
+				errCount.Add(1)
+			}
+		})
+	}
+	wg.Wait()
 
-		if errCount != 2 {
-			t.Error("应该有2个错误的请求")
-		}
-		wg.Wait()
+	if errCount.Load() != 2 {
+		t.Errorf("应该有2个错误的请求，实际为%d", errCount.Load())
+	}
+}
+
+func TestBurstAtCapacity(t *testing.T) {
+	bucket, clk := newTestBucket(5, 1)
+
+	// 空桶可以一次通过等于容量的请求
+	if !bucket.AllowN(5) {
+		t.Error("空桶应该能一次通过等于容量的请求")
+	}
+	if bucket.Allow() {
+		t.Error("桶已满，请求应该被拒绝")
+	}
+
+	// 漏空之后再次允许一次突发
+	clk.Advance(5 * time.Second)
+	if !bucket.AllowN(5) {
+		t.Error("漏空之后应该能再次通过等于容量的请求")
+	}
+}
+
+func TestLongIdle(t *testing.T) {
+	bucket, clk := newTestBucket(10, 1000)
+	bucket.AllowN(10)
+
+	// 长时间空闲后桶被漏空，但不会积累超过容量的余量
+	clk.Advance(100 * 365 * 24 * time.Hour)
+	if !bucket.AllowN(10) {
+		t.Error("长时间空闲后应该能通过等于容量的请求")
+	}
+	if bucket.Allow() {
+		t.Error("空闲不应积累超过容量的余量")
+	}
+
+	// 时钟回拨时不漏水，也不会出错
+	clk.Advance(-time.Hour)
+	if bucket.Allow() {
+		t.Error("时钟回拨时不应漏水")
+	}
+}
+
+func TestAllowN(t *testing.T) {
+	bucket, clk := newTestBucket(10, 10)
+
+	if !bucket.AllowN(5) {
+		t.Error("前5个请求应该通过")
+	}
+	if bucket.AllowN(6) {
+		t.Error("超出容量的6个请求应该被拒绝")
+	}
+	if !bucket.AllowN(5) {
+		t.Error("剩余容量正好为5，应该通过")
+	}
+	if bucket.Allow() {
+		t.Error("桶已满，请求应该被拒绝")
+	}
+	if !bucket.AllowN(0) {
+		t.Error("0个请求总是应该通过")
+	}
+
+	// 每秒漏10份水，300毫秒后可以通过3个请求
+	clk.Advance(300 * time.Millisecond)
+	if !bucket.AllowN(3) {
+		t.Error("300毫秒后应该能通过3个请求")
+	}
+	if bucket.Allow() {
+		t.Error("第4个请求应该被拒绝")
+	}
+}
+
+func TestLeakRemainder(t *testing.T) {
+	// 每纳秒漏0.5个单位，逐纳秒更新时不足一个单位的部分不应丢失
+	bucket := New(10, 0.5*float64(time.Second)/unit)
+	s := &state{water: 5000}
+	for ts := int64(1); ts <= 1000; ts++ {
+		s = bucket.leak(s, ts)
+	}
+
+	if s.water != 4500 {
+		t.Errorf("1000纳秒后水位应为4500，实际为%d", s.water)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	bucket, clk := newTestBucket(2, 1)
+	bucket.AllowN(2)
+
+	r := bucket.Reserve()
+	if !r.OK() {
+		t.Fatal("预约应该有效")
+	}
+	if d := r.Delay(); d != time.Second+1 {
+		t.Errorf("预约应该等待1秒，实际为%v", d)
+	}
+
+	// 预约占用了漏出的容量，后续预约需要等待更久
+	if d := bucket.Reserve().Delay(); d != 2*time.Second+1 {
+		t.Errorf("第二个预约应该等待2秒，实际为%v", d)
+	}
+
+	clk.Advance(r.Delay())
+	if r.Delay() != 0 {
+		t.Error("到达预约时刻后不需要再等待")
+	}
+	if bucket.Allow() {
+		t.Error("漏出的容量已被预约，请求应该被拒绝")
+	}
+
+	if d := bucket.ReserveN(0).Delay(); d != 0 {
+		t.Errorf("0个请求的预约不需要等待，实际为%v", d)
+	}
+}
+
+func TestReserveCancel(t *testing.T) {
+	bucket, clk := newTestBucket(1, 1)
+	bucket.Allow()
+
+	r := bucket.Reserve()
+	r.Cancel()
+	r.Cancel()
+
+	// 重复取消只退回一次水量
+	if d := bucket.Reserve().Delay(); d != time.Second+1 {
+		t.Errorf("取消后的预约应该等待1秒，实际为%v", d)
+	}
+
+	// 到达预约时刻后取消不起作用
+	bucket, clk = newTestBucket(1, 1)
+	bucket.Allow()
+	r = bucket.Reserve()
+	clk.Advance(r.Delay())
+	r.Cancel()
+	if bucket.Allow() {
+		t.Error("已执行的预约取消后不应退回水量")
+	}
+}
+
+func TestReserveExceedsCapacity(t *testing.T) {
+	bucket, _ := newTestBucket(2, 1)
+
+	r := bucket.ReserveN(3)
+	if r.OK() {
+		t.Error("超过容量的预约应该无效")
+	}
+	if r.Delay() != ratelimit.InfDuration {
+		t.Error("无效的预约应该永远无法执行")
+	}
+	r.Cancel()
+
+	err := bucket.WaitN(context.Background(), 3)
+	if !errors.Is(err, ErrExceedsCapacity) {
+		t.Errorf("应该返回ErrExceedsCapacity，实际为%v", err)
+	}
+	if !bucket.AllowN(2) {
+		t.Error("无效的预约不应占用容量")
+	}
+}
+
+func TestWait(t *testing.T) {
+	bucket, clk := newTestBucket(1, 10)
+	ctx := context.Background()
+
+	if err := bucket.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if clk.Waiters() != 0 {
+		t.Error("桶未满时不应等待")
+	}
+
+	// 第二个请求需要等待100毫秒
+	done := make(chan error)
+	go func() {
+		done <- bucket.Wait(ctx)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(100 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("不足100毫秒时不应返回")
+	default:
+	}
+
+	clk.Advance(time.Nanosecond)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bucket.WaitN(ctx, 0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWaitCancel(t *testing.T) {
+	bucket, clk := newTestBucket(1, 10)
+	bucket.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- bucket.Wait(ctx)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("应该返回context.Canceled，实际为%v", err)
+	}
+
+	// 取消的请求退回了水量，下一个请求只需等到第一个请求漏完
+	if d := bucket.Reserve().Delay(); d != 90*time.Millisecond+1 {
+		t.Errorf("取消后应该只需等待90毫秒，实际为%v", d)
+	}
+
+	// 已取消的ctx直接返回错误
+	if err := bucket.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("应该返回context.Canceled，实际为%v", err)
+	}
+}
+
+func TestWaitDeadline(t *testing.T) {
+	// 每小时漏1份水，第二个请求需要等待1小时
+	bucket, clk := newTestBucket(1, 1.0/3600)
+	bucket.Allow()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := bucket.Wait(ctx); !errors.Is(err, ratelimit.ErrDeadline) {
+		t.Errorf("等待时间超过截止时间，应该返回ErrDeadline，实际为%v", err)
+	}
+	if clk.Waiters() != 0 {
+		t.Error("超过截止时间时应立即返回")
+	}
+
+	// 失败的等待退回了水量
+	if d := bucket.Reserve().Delay(); d < time.Hour || d > time.Hour+time.Microsecond {
+		t.Errorf("失败的等待不应占用容量，预约需要等待1小时，实际为%v", d)
+	}
+}
+
+func TestConformance(t *testing.T) {
+	ratelimittest.Run(t, func(limit float64, burst int, clk clock.Clock) ratelimit.Limiter {
+		return New(int64(burst), limit, WithClock(clk))
 	})
 }
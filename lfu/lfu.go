@@ -1,121 +1,337 @@
+// Package lfu 实现了 O(1) 的LFU缓存
+//
+// 缓存维护一个按访问频率升序排列的频率桶链表，每个桶保存该频率下的所有元素，
+// 桶内按访问先后排列，头部是最早访问的元素。每个元素记录自己所在的桶，因此：
+//
+//   - 访问元素时只需把它移动到相邻的下一个频率桶（不存在时在当前桶之后创建）；
+//   - 淘汰时取第一个桶（即最小频率）头部的元素；
+//   - 桶变空时立即从链表中删除，第一个桶始终是当前的最小频率。
+//
+// 以上操作都只涉及常数个链表节点，时间复杂度为 O(1)。
+//
+// 访问频率默认只增不减，过去的热点数据可能永远无法被淘汰。通过 WithHalving
+// 或 WithDynamicAging 可以启用频率老化，二者同时指定时以后者为准。
 package lfu
 
 import (
+	"algorithm/cache"
 	"algorithm/double_linked_list"
-	"sync/atomic"
+	"iter"
 )
 
+// item 是缓存中的一个元素
+type item[K comparable, V any] struct {
+	key    K
+	value  V
+	bucket *double_linked_list.DNode[*bucket[K, V]] // 元素所在的频率桶
+}
+
+// bucket 是访问频率相同的元素集合
+type bucket[K comparable, V any] struct {
+	freq  int
+	items *double_linked_list.DoubleLinkedList[*item[K, V]]
+}
+
 type LFU[K comparable, V any] struct {
-	entries     map[K]V
-	minFreq     atomic.Int32
-	keyToFreq   map[K]int
-	keyToNode   map[K]*double_linked_list.DNode[K]
-	freqToDLink map[int]*double_linked_list.DoubleLinkedList[K]
-	capacity    int
+	items    map[K]*double_linked_list.DNode[*item[K, V]]
+	buckets  *double_linked_list.DoubleLinkedList[*bucket[K, V]] // 按频率升序排列
+	capacity int
+	onEvict  func(key K, value V, reason cache.EvictReason)
+	aging    aging
+	accesses int // 上次减半以来的访问次数
+	age      int // LFU-DA中最近一次被淘汰元素的频率
+	stats    cache.StatsCounter
+	codec    cache.Codec
 }
 
-func NewLFU[K comparable, V any](capacity int) *LFU[K, V] {
+var _ cache.Cache[string, int] = (*LFU[string, int])(nil)
+
+// New 根据构造选项创建LFU缓存，容量通过 cache.WithCapacity 指定
+func New[K comparable, V any](opts ...cache.Option) *LFU[K, V] {
+	o := cache.NewOptions(opts...)
+
 	return &LFU[K, V]{
-		entries:     make(map[K]V),
-		keyToFreq:   make(map[K]int),
-		keyToNode:   make(map[K]*double_linked_list.DNode[K]),
-		freqToDLink: make(map[int]*double_linked_list.DoubleLinkedList[K]),
-		capacity:    capacity,
+		items:    make(map[K]*double_linked_list.DNode[*item[K, V]]),
+		buckets:  double_linked_list.NewDoubleLinkedList[*bucket[K, V]](),
+		capacity: o.Capacity,
+		onEvict:  cache.OnEvict[K, V](o),
+		aging:    agingOf(o),
+		codec:    o.Codec,
 	}
 }
 
+// NewLFU 创建容量为capacity的LFU缓存
+func NewLFU[K comparable, V any](capacity int, opts ...cache.Option) *LFU[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// Get 返回key对应的值，并将其访问频率加1
 func (l *LFU[K, V]) Get(key K) (V, bool) {
-	value, ok := l.entries[key]
+	var zero V
+	node, ok := l.items[key]
 	if !ok {
-		return value, false
+		l.stats.Miss()
+		return zero, false
 	}
 
-	currentFreq := l.keyToFreq[key]
-	dl := l.freqToDLink[currentFreq]
-	node := l.keyToNode[key]
-	dl.Remove(node)
+	l.stats.Hit()
+	node = l.increment(node)
+	value := node.Val.value
+	l.accessed()
 
-	if dl.Size() == 0 {
-		l.compareAndSwap(currentFreq, currentFreq+1)
-	}
+	return value, true
+}
 
-	freq := currentFreq + 1
-	nowDL, ok := l.freqToDLink[freq]
+// Peek 返回key对应的值，不增加访问频率
+func (l *LFU[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	node, ok := l.items[key]
 	if !ok {
-		nowDL = double_linked_list.NewDoubleLinkedList[K]()
-		l.freqToDLink[freq] = nowDL
+		return zero, false
 	}
 
-	l.keyToFreq[key] = freq
-	newNode := nowDL.Append(key)
-	l.keyToNode[key] = newNode
+	return node.Val.value, true
+}
 
-	return value, true
+// Contains 判断key是否存在，不增加访问频率
+func (l *LFU[K, V]) Contains(key K) bool {
+	_, ok := l.items[key]
+	return ok
 }
 
+// Put 写入元素，已存在的元素会更新值并增加访问频率
 func (l *LFU[K, V]) Put(key K, value V) error {
-	if _, ok := l.entries[key]; ok {
-		l.Get(key)
-		l.entries[key] = value
+	defer l.accessed()
+
+	if node, ok := l.items[key]; ok {
+		node = l.increment(node)
+		old := node.Val.value
+		node.Val.value = value
+		l.evicted(key, old, cache.EvictReplaced)
 
 		return nil
 	}
 
-	// 当容量达到上限时，需要淘汰元素
-	if len(l.entries) >= l.capacity {
-		min := l.getMinFreq()
-		minDl, ok := l.freqToDLink[min]
-		if !ok {
-			// 如果最小频率对应的链表不存在，说明LFU可能有问题
-			return nil
-		}
+	l.stats.Insert()
+
+	// 容量为0时新元素写入后立即被淘汰
+	if l.capacity <= 0 {
+		l.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	// 当容量达到上限时，淘汰最小频率中最早访问的元素
+	if len(l.items) >= l.capacity {
+		l.evictOne()
+	}
+
+	// 新元素的频率为1，LFU-DA中为年龄加1
+	b := l.bucketFor(l.age + 1)
+	l.items[key] = b.Val.items.Append(&item[K, V]{key: key, value: value, bucket: b})
+
+	return nil
+}
+
+// Remove 删除元素，返回元素是否存在
+func (l *LFU[K, V]) Remove(key K) bool {
+	node, ok := l.items[key]
+	if !ok {
+		return false
+	}
+
+	it := node.Val
+	l.unlink(node)
+	l.evicted(it.key, it.value, cache.EvictRemoved)
+
+	return true
+}
+
+// Len 返回元素数量
+func (l *LFU[K, V]) Len() int {
+	return len(l.items)
+}
+
+// Stats 返回统计信息，只有 Get 计入命中或未命中
+func (l *LFU[K, V]) Stats() cache.Stats {
+	return l.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (l *LFU[K, V]) ResetStats() {
+	l.stats.Reset()
+}
+
+// Keys 按访问频率从高到低返回所有键，频率相同时最近访问的在前
+func (l *LFU[K, V]) Keys() []K {
+	keys := make([]K, 0, len(l.items))
+	for key := range l.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
 
-		// 从最小频率链表中移除头部元素（最早访问的）
-		deleteKey, err := minDl.RemoveHead()
-		if err != nil {
-			return err
+// All 返回按访问频率从高到低遍历所有元素的迭代器，频率相同时最近访问的在前，
+// 遍历不改变访问频率
+func (l *LFU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for b := range l.buckets.Backward() {
+			for node := range b.Val.items.Backward() {
+				if !yield(node.Val.key, node.Val.value) {
+					return
+				}
+			}
 		}
+	}
+}
+
+// Clear 删除所有元素，每个元素都会以 cache.EvictRemoved 触发回调
+func (l *LFU[K, V]) Clear() {
+	buckets := l.buckets
+	l.items = make(map[K]*double_linked_list.DNode[*item[K, V]])
+	l.buckets = double_linked_list.NewDoubleLinkedList[*bucket[K, V]]()
+	l.accesses = 0
+	l.age = 0
 
-		// 如果移除后链表为空，删除该频率的映射
-		if minDl.Size() == 0 {
-			delete(l.freqToDLink, min)
+	for b := range buckets.Nodes() {
+		for node := range b.Val.items.Nodes() {
+			l.evicted(node.Val.key, node.Val.value, cache.EvictRemoved)
 		}
+	}
+}
 
-		// 从所有映射中删除该键
-		delete(l.entries, deleteKey)
-		delete(l.keyToNode, deleteKey)
-		delete(l.keyToFreq, deleteKey)
+// Resize 修改容量，容量缩小时立即淘汰访问频率最低的元素，返回淘汰的数量
+func (l *LFU[K, V]) Resize(capacity int) int {
+	l.capacity = capacity
+
+	evicted := 0
+	for len(l.items) > max(capacity, 0) && l.evictOne() {
+		evicted++
 	}
 
-	// 创建或获取频率为1的链表
-	dl, ok := l.freqToDLink[1]
-	if !ok {
-		dl = double_linked_list.NewDoubleLinkedList[K]()
-		l.freqToDLink[1] = dl
+	return evicted
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+func (l *LFU[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	l.onEvict = fn
+}
+
+// increment 将元素移动到下一个频率桶，返回元素的新节点
+func (l *LFU[K, V]) increment(node *double_linked_list.DNode[*item[K, V]]) *double_linked_list.DNode[*item[K, V]] {
+	it := node.Val
+	cur := it.bucket
+	freq := cur.Val.freq + 1
+
+	next := l.buckets.Next(cur)
+	if next == nil || next.Val.freq != freq {
+		l.buckets.InsertAfter(cur, l.newBucket(freq))
+		next = l.buckets.Next(cur)
 	}
 
-	// 将新键添加到频率为1的链表中
-	node := dl.Append(key)
+	l.unlink(node)
+	it.bucket = next
+	node = next.Val.items.Append(it)
+	l.items[it.key] = node
 
-	// 更新所有映射
-	l.keyToNode[key] = node
-	l.keyToFreq[key] = 1
-	l.entries[key] = value
+	return node
+}
 
-	// 由于是新键，最小频率一定是1
-	l.updateMinFreq(1)
+// unlink 将元素从所在的桶和索引中删除，桶变空时一并删除
+func (l *LFU[K, V]) unlink(node *double_linked_list.DNode[*item[K, V]]) {
+	it := node.Val
+	b := it.bucket
+	b.Val.items.Remove(node)
+	if b.Val.items.Size() == 0 {
+		l.buckets.Remove(b)
+	}
 
-	return nil
+	delete(l.items, it.key)
 }
 
-func (l *LFU[K, V]) getMinFreq() int {
-	return int(l.minFreq.Load())
+// evictOne 淘汰最小频率桶头部（最早访问的）元素
+func (l *LFU[K, V]) evictOne() bool {
+	first := l.buckets.Front()
+	if first == nil {
+		return false
+	}
+
+	node := first.Val.items.Front()
+	it := node.Val
+	if l.aging.mode == agingDynamic {
+		l.age = first.Val.freq
+	}
+	l.unlink(node)
+	l.evicted(it.key, it.value, cache.EvictCapacity)
+
+	return true
 }
 
-func (l *LFU[K, V]) updateMinFreq(freq int) {
-	l.minFreq.Store(int32(freq))
+// bucketFor 返回频率为freq的桶，不存在时按顺序创建
+//
+// 所有元素的频率都不低于年龄，因此freq为年龄加1时最多只需检查前两个桶。
+func (l *LFU[K, V]) bucketFor(freq int) *double_linked_list.DNode[*bucket[K, V]] {
+	var prev *double_linked_list.DNode[*bucket[K, V]]
+	for b := l.buckets.Front(); b != nil && b.Val.freq <= freq; b = l.buckets.Next(b) {
+		if b.Val.freq == freq {
+			return b
+		}
+		prev = b
+	}
+
+	if prev == nil {
+		l.buckets.Prepend(l.newBucket(freq))
+		return l.buckets.Front()
+	}
+
+	l.buckets.InsertAfter(prev, l.newBucket(freq))
+	return l.buckets.Next(prev)
+}
+
+// accessed 记录一次访问，启用减半老化时每累计一个周期将所有频率减半
+func (l *LFU[K, V]) accessed() {
+	if l.aging.mode != agingHalving {
+		return
+	}
+
+	l.accesses++
+	if l.accesses >= l.aging.period {
+		l.accesses = 0
+		l.halve()
+	}
 }
 
-func (l *LFU[K, V]) compareAndSwap(old, new int) {
-	l.minFreq.CompareAndSwap(int32(old), int32(new))
+// halve 将所有桶的频率减半（最低为1），频率相同的相邻桶合并
+//
+// 合并时原先频率较高的元素排在后面，在同一频率中更晚被淘汰。
+func (l *LFU[K, V]) halve() {
+	var prev *double_linked_list.DNode[*bucket[K, V]]
+	for b := range l.buckets.Nodes() {
+		b.Val.freq = max(b.Val.freq/2, 1)
+		if prev == nil || prev.Val.freq != b.Val.freq {
+			prev = b
+			continue
+		}
+
+		for node := range b.Val.items.Nodes() {
+			it := node.Val
+			it.bucket = prev
+			l.items[it.key] = prev.Val.items.Append(it)
+		}
+		l.buckets.Remove(b)
+	}
+}
+
+func (l *LFU[K, V]) newBucket(freq int) *bucket[K, V] {
+	return &bucket[K, V]{
+		freq:  freq,
+		items: double_linked_list.NewDoubleLinkedList[*item[K, V]](),
+	}
+}
+
+func (l *LFU[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	l.stats.Evict(reason)
+	if l.onEvict != nil {
+		l.onEvict(key, value, reason)
+	}
 }
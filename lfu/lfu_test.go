@@ -1,11 +1,23 @@
 package lfu
 
 import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
 	"testing"
 
 	"github.com/stretchr/testify/suite"
 )
 
+// freqOf 返回key当前的访问频率，不存在时返回0
+func freqOf[K comparable, V any](l *LFU[K, V], key K) int {
+	node, ok := l.items[key]
+	if !ok {
+		return 0
+	}
+
+	return node.Val.bucket.Val.freq
+}
+
 // LFUTestSuite 是LFU缓存的测试套件
 type LFUTestSuite struct {
 	suite.Suite
@@ -46,13 +58,13 @@ func (s *LFUTestSuite) TestPutAndGet() {
 	val, ok = s.lfu.Get("key2")
 	s.True(ok)
 	s.Equal(2, val)
-	s.Equal(2, s.lfu.keyToFreq["key2"])
+	s.Equal(2, freqOf(s.lfu, "key2"))
 
 
 	err = s.lfu.Put("key2", 3)
 	s.Nil(err)
 
-	s.Equal(3, s.lfu.keyToFreq["key2"])
+	s.Equal(3, freqOf(s.lfu, "key2"))
 }
 
 // TestUpdateValue 测试更新已有元素的值
@@ -81,21 +93,21 @@ func (s *LFUTestSuite) TestFrequencyUpdate() {
 	// 第一次访问
 	_, ok := s.lfu.Get("key1")
 	s.True(ok)
-	s.Equal(2, s.lfu.keyToFreq["key1"])
+	s.Equal(2, freqOf(s.lfu, "key1"))
 
 	// 第二次访问
 	_, ok = s.lfu.Get("key1")
 	s.True(ok)
-	s.Equal(3, s.lfu.keyToFreq["key1"])
+	s.Equal(3, freqOf(s.lfu, "key1"))
 
 	// 添加第二个元素并访问
 	err = s.lfu.Put("key2", 2)
 	s.NoError(err)
-	s.Equal(1, s.lfu.keyToFreq["key2"])
+	s.Equal(1, freqOf(s.lfu, "key2"))
 
 	_, ok = s.lfu.Get("key2")
 	s.True(ok)
-	s.Equal(2, s.lfu.keyToFreq["key2"])
+	s.Equal(2, freqOf(s.lfu, "key2"))
 }
 
 // TestEviction 测试淘汰机制
@@ -148,9 +160,9 @@ func (s *LFUTestSuite) TestEvictionWithFrequency() {
 	s.True(ok)
 
 	// 此时频率: key1=3, key2=2, key3=1
-	s.Equal(3, s.lfu.keyToFreq["key1"])
-	s.Equal(2, s.lfu.keyToFreq["key2"])
-	s.Equal(1, s.lfu.keyToFreq["key3"])
+	s.Equal(3, freqOf(s.lfu, "key1"))
+	s.Equal(2, freqOf(s.lfu, "key2"))
+	s.Equal(1, freqOf(s.lfu, "key3"))
 
 	// 添加新元素，应该淘汰频率最低的key3
 	err = s.lfu.Put("key4", 4)
@@ -285,13 +297,13 @@ func (s *LFUTestSuite) TestPutDuplicateKey() {
 	// 第一次插入
 	err := s.lfu.Put("key1", 1)
 	s.NoError(err)
-	s.Equal(1, len(s.lfu.entries))
+	s.Equal(1, s.lfu.Len())
 
 	// 第二次插入相同的键
 	err = s.lfu.Put("key1", 10)
 	s.NoError(err)
 	// 容量不应该增加
-	s.Equal(1, len(s.lfu.entries))
+	s.Equal(1, s.lfu.Len())
 
 	// 验证值被更新
 	val, ok := s.lfu.Get("key1")
@@ -317,18 +329,276 @@ func (s *LFUTestSuite) TestEvictionWhenFrequencyListEmpty() {
 	_, ok = s.lfu.Get("key3")
 	s.True(ok)
 
-	// 此时频率为1的列表应该为空
-	s.Equal(0, s.lfu.freqToDLink[1].Size())
+	// 此时频率为1的桶应该为空并已被删除
+	s.Equal(2, s.lfu.buckets.Front().Val.freq)
+	s.Equal(1, s.lfu.buckets.Size())
 
 	// 添加第四个元素，应该淘汰其中一个元素
 	err = s.lfu.Put("key4", 4)
 	s.NoError(err)
 
 	// 验证缓存中只有4个元素
-	s.Equal(3, len(s.lfu.entries))
+	s.Equal(3, s.lfu.Len())
+}
+
+// TestOnEvict 测试淘汰和覆盖时触发回调
+func (s *LFUTestSuite) TestOnEvict() {
+	type evicted struct {
+		key    string
+		value  int
+		reason cache.EvictReason
+	}
+	var got []evicted
+	s.lfu.OnEvict(func(key string, value int, reason cache.EvictReason) {
+		got = append(got, evicted{key, value, reason})
+	})
+
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.NoError(s.lfu.Put("key1", 10))
+	s.NoError(s.lfu.Put("key4", 4))
+
+	s.Equal([]evicted{
+		{"key1", 1, cache.EvictReplaced},
+		{"key2", 2, cache.EvictCapacity},
+	}, got)
+
+	// 取消回调
+	s.lfu.OnEvict(nil)
+	s.NoError(s.lfu.Put("key5", 5))
+	s.Len(got, 2)
+}
+
+// TestRemove 测试删除元素后仍能正确淘汰
+func (s *LFUTestSuite) TestRemove() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.lfu.Get("key2")
+
+	s.True(s.lfu.Remove("key1"))
+	s.False(s.lfu.Remove("key1"))
+	s.Equal(1, s.lfu.Len())
+
+	// 频率为1的元素已被删除，淘汰时需要找到新的最小频率
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key3")
+	s.NoError(s.lfu.Put("key4", 4))
+	s.lfu.Get("key4")
+	s.NoError(s.lfu.Put("key5", 5))
+	s.Equal(3, s.lfu.Len())
+	s.False(s.lfu.Contains("key2"))
+}
+
+// TestPeek 测试Peek不增加访问频率
+func (s *LFUTestSuite) TestPeek() {
+	s.NoError(s.lfu.Put("key1", 1))
+
+	val, ok := s.lfu.Peek("key1")
+	s.True(ok)
+	s.Equal(1, val)
+	s.Equal(1, freqOf(s.lfu, "key1"))
+}
+
+// TestKeysOrder 测试Keys按访问频率从高到低返回
+func (s *LFUTestSuite) TestKeysOrder() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key2")
+	s.lfu.Get("key2")
+	s.lfu.Get("key1")
+
+	s.Equal([]string{"key2", "key1", "key3"}, s.lfu.Keys())
+}
+
+// TestAll 测试All按访问频率从高到低遍历，且不改变访问频率
+func (s *LFUTestSuite) TestAll() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key1")
+	s.lfu.Get("key1")
+	s.lfu.Get("key2")
+
+	var keys []string
+	var values []int
+	for key, value := range s.lfu.All() {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	s.Equal([]string{"key1", "key2", "key3"}, keys)
+	s.Equal([]int{1, 2, 3}, values)
+	s.Equal(3, freqOf(s.lfu, "key1"))
+	s.Equal(1, freqOf(s.lfu, "key3"))
+
+	// 提前停止遍历
+	keys = nil
+	for key := range s.lfu.All() {
+		keys = append(keys, key)
+		break
+	}
+	s.Equal([]string{"key1"}, keys)
+}
+
+// TestAllRemove 测试遍历期间删除当前元素，包括删除桶中唯一的元素
+func (s *LFUTestSuite) TestAllRemove() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key1")
+
+	var keys []string
+	for key := range s.lfu.All() {
+		keys = append(keys, key)
+		if key != "key2" {
+			s.True(s.lfu.Remove(key))
+		}
+	}
+	s.Equal([]string{"key1", "key3", "key2"}, keys)
+	s.Equal([]string{"key2"}, s.lfu.Keys())
+	s.Equal(1, s.lfu.buckets.Size())
+}
+
+// TestResize 测试缩小容量时淘汰访问频率最低的元素
+func (s *LFUTestSuite) TestResize() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key3")
+
+	s.Equal(2, s.lfu.Resize(1))
+	s.Equal([]string{"key3"}, s.lfu.Keys())
+
+	// 容量为0时写入的元素立即被淘汰
+	s.Equal(1, s.lfu.Resize(0))
+	s.NoError(s.lfu.Put("key4", 4))
+	s.Equal(0, s.lfu.Len())
+}
+
+// TestResizeHooks 测试Resize按频率从低到高淘汰并触发回调，扩大容量后可以容纳更多元素
+func (s *LFUTestSuite) TestResizeHooks() {
+	var evicted []string
+	l := NewLFU[string, int](4, cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		s.Equal(cache.EvictCapacity, reason)
+		evicted = append(evicted, key)
+	}))
+	s.NoError(l.Put("key1", 1))
+	s.NoError(l.Put("key2", 2))
+	s.NoError(l.Put("key3", 3))
+	s.NoError(l.Put("key4", 4))
+	l.Get("key1")
+	l.Get("key1")
+	l.Get("key3")
+
+	s.Equal(3, l.Resize(1))
+	s.Equal([]string{"key2", "key4", "key3"}, evicted)
+	s.Equal([]string{"key1"}, l.Keys())
+
+	// 扩大容量不淘汰元素
+	evicted = nil
+	s.Equal(0, l.Resize(3))
+	s.NoError(l.Put("key5", 5))
+	s.NoError(l.Put("key6", 6))
+	s.Empty(evicted)
+	s.Equal(3, l.Len())
+}
+
+// TestBucketsInvariant 测试频率桶始终按频率升序排列且没有空桶
+func (s *LFUTestSuite) TestBucketsInvariant() {
+	lfu := NewLFU[int, int](8)
+	ops := []int{1, 2, 3, 1, 1, 2, 4, 5, 6, 7, 8, 9, 3, 3, 3, 9, 10, 1}
+	for i, key := range ops {
+		if i%3 == 0 {
+			lfu.Get(key)
+		}
+		s.NoError(lfu.Put(key, i))
+
+		prev := 0
+		total := 0
+		for b := range lfu.buckets.Nodes() {
+			s.Greater(b.Val.freq, prev)
+			s.Greater(b.Val.items.Size(), 0)
+			for node := range b.Val.items.Nodes() {
+				s.Equal(b, node.Val.bucket)
+				s.Equal(node, lfu.items[node.Val.key])
+			}
+			prev = b.Val.freq
+			total += b.Val.items.Size()
+		}
+		s.Equal(lfu.Len(), total)
+		s.LessOrEqual(lfu.Len(), 8)
+	}
+}
+
+// TestMinFreqAfterRemove 测试删除最小频率的所有元素后淘汰下一个最小频率
+func (s *LFUTestSuite) TestMinFreqAfterRemove() {
+	s.NoError(s.lfu.Put("key1", 1))
+	s.NoError(s.lfu.Put("key2", 2))
+	s.NoError(s.lfu.Put("key3", 3))
+	s.lfu.Get("key2")
+	s.lfu.Get("key3")
+	s.lfu.Get("key3")
+
+	s.True(s.lfu.Remove("key1"))
+	s.Equal(2, s.lfu.buckets.Front().Val.freq)
+
+	s.NoError(s.lfu.Put("key4", 4))
+	s.NoError(s.lfu.Put("key5", 5))
+
+	// key4频率为1，是最小频率中最早访问的元素
+	s.False(s.lfu.Contains("key4"))
+	s.True(s.lfu.Contains("key2"))
+	s.True(s.lfu.Contains("key3"))
+	s.True(s.lfu.Contains("key5"))
+}
+
+// TestStats 测试命中、未命中、写入、更新和淘汰的统计
+func (s *LFUTestSuite) TestStats() {
+	s.lfu.Put("key1", 1)
+	s.lfu.Put("key1", 10)
+	s.lfu.Put("key2", 2)
+	s.lfu.Put("key3", 3)
+	s.lfu.Get("key1")
+	s.lfu.Get("key3")
+	s.lfu.Get("key9")
+	s.lfu.Peek("key2")
+	s.lfu.Put("key4", 4)
+	s.lfu.Remove("key4")
+
+	st := s.lfu.Stats()
+	s.Equal(uint64(2), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(4), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(uint64(1), st.Evictions[cache.EvictRemoved])
+	s.Equal(2, st.Size)
+	s.InDelta(2.0/3, st.HitRatio(), 1e-9)
+
+	s.lfu.ResetStats()
+	st = s.lfu.Stats()
+	s.Zero(st.Hits)
+	s.Zero(st.Evicted())
+	s.Equal(2, st.Size)
+
+	// 容量为0时写入后立即被淘汰
+	l := NewLFU[string, int](0)
+	l.Put("key1", 1)
+	st = l.Stats()
+	s.Equal(uint64(1), st.Insertions)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(0, st.Size)
 }
 
 // TestLRU 运行所有LFU测试
 func TestLFU(t *testing.T) {
 	suite.Run(t, new(LFUTestSuite))
 }
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
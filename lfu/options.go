@@ -0,0 +1,38 @@
+package lfu
+
+import "algorithm/cache"
+
+// 频率老化策略
+const (
+	agingNone    = iota
+	agingHalving // 周期性将所有频率减半
+	agingDynamic // LFU-DA：新元素的频率从缓存的"年龄"开始
+)
+
+type agingKey struct{}
+
+type aging struct {
+	mode   int
+	period int
+}
+
+// WithHalving 每累计every次访问（Get命中和Put）将所有元素的访问频率减半，
+// 频率最低减到1，使过去的热点数据在访问模式变化后能够被淘汰
+func WithHalving(every int) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(agingKey{}, aging{mode: agingHalving, period: max(every, 1)})
+	}
+}
+
+// WithDynamicAging 启用LFU-DA：缓存记录最近一次被淘汰元素的频率作为"年龄"，
+// 新元素的频率从年龄加1开始，而不是从1开始，使新元素能够与过去的热点数据竞争
+func WithDynamicAging() cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(agingKey{}, aging{mode: agingDynamic})
+	}
+}
+
+func agingOf(o *cache.Options) aging {
+	a, _ := o.Value(agingKey{}).(aging)
+	return a
+}
@@ -0,0 +1,124 @@
+package lfu
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// AgingTestSuite 是LFU频率老化的测试套件
+type AgingTestSuite struct {
+	suite.Suite
+}
+
+// shiftWorkload 先让old成为热点，再切换到只访问新键的负载，返回old是否仍在缓存中
+func shiftWorkload(l *LFU[string, int]) bool {
+	l.Put("old", 0)
+	for range 100 {
+		l.Get("old")
+	}
+
+	for i := range 300 {
+		key := "new" + strconv.Itoa(i%3)
+		if _, ok := l.Get(key); !ok {
+			l.Put(key, i)
+		}
+	}
+
+	return l.Contains("old")
+}
+
+// TestNoAging 测试未启用老化时过去的热点数据无法被淘汰
+func (s *AgingTestSuite) TestNoAging() {
+	s.True(shiftWorkload(NewLFU[string, int](3)))
+}
+
+// TestHalvingEvictsFormerlyHot 测试周期性减半后过去的热点数据最终被淘汰
+func (s *AgingTestSuite) TestHalvingEvictsFormerlyHot() {
+	l := NewLFU[string, int](3, WithHalving(20))
+	s.False(shiftWorkload(l))
+	s.ElementsMatch([]string{"new0", "new1", "new2"}, l.Keys())
+}
+
+// TestDynamicAgingEvictsFormerlyHot 测试LFU-DA中过去的热点数据最终被淘汰
+func (s *AgingTestSuite) TestDynamicAgingEvictsFormerlyHot() {
+	l := NewLFU[string, int](3, WithDynamicAging())
+	s.False(shiftWorkload(l))
+	s.ElementsMatch([]string{"new0", "new1", "new2"}, l.Keys())
+}
+
+// TestHalve 测试减半后频率相同的桶被合并，原频率较高的元素排在后面
+func (s *AgingTestSuite) TestHalve() {
+	l := NewLFU[string, int](4, WithHalving(1000))
+	l.Put("key1", 1)
+	l.Put("key2", 2)
+	l.Get("key2")
+	l.Put("key3", 3)
+	l.Get("key3")
+	l.Get("key3")
+	l.Put("key4", 4)
+	for range 5 {
+		l.Get("key4")
+	}
+
+	// 频率: key1=1, key2=2, key3=3, key4=6
+	l.halve()
+	s.Equal(1, freqOf(l, "key1"))
+	s.Equal(1, freqOf(l, "key2"))
+	s.Equal(1, freqOf(l, "key3"))
+	s.Equal(3, freqOf(l, "key4"))
+	s.Equal(2, l.buckets.Size())
+	s.Equal([]string{"key4", "key3", "key2", "key1"}, l.Keys())
+
+	// 最低减到1
+	l.halve()
+	s.Equal(1, freqOf(l, "key1"))
+	s.Equal(1, freqOf(l, "key4"))
+	s.Equal(1, l.buckets.Size())
+}
+
+// TestHalvingPeriod 测试访问次数达到周期时自动减半
+func (s *AgingTestSuite) TestHalvingPeriod() {
+	l := NewLFU[string, int](2, WithHalving(4))
+	l.Put("key1", 1)
+	l.Get("key1")
+	l.Get("key1")
+	s.Equal(3, freqOf(l, "key1"))
+
+	// 第4次访问后减半
+	l.Get("key1")
+	s.Equal(2, freqOf(l, "key1"))
+	s.Equal(0, l.accesses)
+}
+
+// TestDynamicAgingStart 测试LFU-DA中新元素的频率从年龄加1开始
+func (s *AgingTestSuite) TestDynamicAgingStart() {
+	l := NewLFU[string, int](2, WithDynamicAging())
+	l.Put("key1", 1)
+	l.Put("key2", 2)
+	for range 4 {
+		l.Get("key1")
+	}
+	l.Get("key2")
+
+	// 淘汰频率为2的key2，年龄变为2
+	l.Put("key3", 3)
+	s.Equal(2, l.age)
+	s.Equal(3, freqOf(l, "key3"))
+	s.False(l.Contains("key2"))
+
+	// 淘汰频率为3的key3，年龄变为3
+	l.Put("key4", 4)
+	s.Equal(3, l.age)
+	s.Equal(4, freqOf(l, "key4"))
+
+	// 清空后年龄归零
+	l.Clear()
+	s.Equal(0, l.age)
+}
+
+// TestAging 运行所有频率老化测试
+func TestAging(t *testing.T) {
+	suite.Run(t, new(AgingTestSuite))
+}
@@ -0,0 +1,90 @@
+package lfu
+
+import (
+	"algorithm/cache"
+	"io"
+)
+
+// snapshotKind 标识LFU的快照
+const snapshotKind = "lfu"
+
+// record 是快照中的一个元素
+type record[K comparable, V any] struct {
+	Key   K
+	Value V
+	Freq  int
+}
+
+// snapshot 是快照的内容
+type snapshot[K comparable, V any] struct {
+	Age      int
+	Accesses int
+	Items    []record[K, V] // 按淘汰顺序排列，最先被淘汰的在前
+}
+
+// Snapshot 把所有元素及其访问频率按淘汰顺序写入w
+//
+// 编解码方式通过 cache.WithCodec 指定，默认使用gob。
+func (l *LFU[K, V]) Snapshot(w io.Writer) error {
+	snap := snapshot[K, V]{
+		Age:      l.age,
+		Accesses: l.accesses,
+		Items:    make([]record[K, V], 0, len(l.items)),
+	}
+	for b := range l.buckets.Nodes() {
+		for node := range b.Val.items.Nodes() {
+			snap.Items = append(snap.Items, record[K, V]{node.Val.key, node.Val.value, b.Val.freq})
+		}
+	}
+
+	return cache.WriteSnapshot(w, l.codec, snapshotKind, snap)
+}
+
+// Restore 用 Snapshot 写入的快照替换缓存的内容，恢复后的访问频率和淘汰顺序与快照时相同
+//
+// 快照校验失败时返回错误，缓存保持不变。恢复前已有的元素以 cache.EvictRemoved
+// 触发回调；超出容量时最先被淘汰的元素被淘汰。
+func (l *LFU[K, V]) Restore(r io.Reader) error {
+	var snap snapshot[K, V]
+	if err := cache.ReadSnapshot(r, l.codec, snapshotKind, &snap); err != nil {
+		return err
+	}
+
+	l.Clear()
+	l.age = snap.Age
+	l.accesses = snap.Accesses
+	for _, rec := range snap.Items {
+		l.restore(rec)
+	}
+
+	return nil
+}
+
+// restore 按记录的频率写入一个元素
+func (l *LFU[K, V]) restore(rec record[K, V]) {
+	if node, ok := l.items[rec.Key]; ok {
+		it := node.Val
+		l.unlink(node)
+		l.evicted(it.key, it.value, cache.EvictReplaced)
+	} else {
+		l.stats.Insert()
+	}
+
+	if l.capacity <= 0 {
+		l.evicted(rec.Key, rec.Value, cache.EvictCapacity)
+		return
+	}
+	if len(l.items) >= l.capacity {
+		l.evictOne()
+	}
+
+	// 记录按频率升序排列，通常只需追加到最后一个桶
+	freq := max(rec.Freq, 1)
+	b := l.buckets.Back()
+	if b == nil || b.Val.freq < freq {
+		b = l.buckets.Append(l.newBucket(freq))
+	} else if b.Val.freq != freq {
+		b = l.bucketFor(freq)
+	}
+	l.items[rec.Key] = b.Val.items.Append(&item[K, V]{key: rec.Key, value: rec.Value, bucket: b})
+}
@@ -0,0 +1,118 @@
+package lfu
+
+import (
+	"algorithm/cache"
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SnapshotTestSuite 是LFU快照的测试套件
+type SnapshotTestSuite struct {
+	suite.Suite
+}
+
+// evictionOrder 依次淘汰所有元素，返回淘汰的顺序
+func evictionOrder(l *LFU[string, int]) []string {
+	var order []string
+	l.OnEvict(func(key string, value int, reason cache.EvictReason) {
+		order = append(order, key)
+	})
+	l.Resize(0)
+
+	return order
+}
+
+// TestSameEvictionOrder 测试恢复后的缓存与原缓存的访问频率和淘汰顺序完全相同
+func (s *SnapshotTestSuite) TestSameEvictionOrder() {
+	for _, codec := range []cache.Codec{cache.GobCodec, cache.JSONCodec} {
+		orig := NewLFU[string, int](8, cache.WithCodec(codec))
+		for i := range 8 {
+			key := "key" + strconv.Itoa(i)
+			orig.Put(key, i)
+			for range i % 3 {
+				orig.Get(key)
+			}
+		}
+
+		var buf bytes.Buffer
+		s.NoError(orig.Snapshot(&buf))
+
+		restored := NewLFU[string, int](8, cache.WithCodec(codec))
+		s.NoError(restored.Restore(&buf))
+		s.Equal(orig.Keys(), restored.Keys())
+		for i := range 8 {
+			key := "key" + strconv.Itoa(i)
+			s.Equal(freqOf(orig, key), freqOf(restored, key))
+		}
+
+		s.Equal(evictionOrder(orig), evictionOrder(restored))
+	}
+}
+
+// TestRestoreAge 测试恢复LFU-DA的年龄
+func (s *SnapshotTestSuite) TestRestoreAge() {
+	orig := NewLFU[string, int](1, WithDynamicAging())
+	orig.Put("key1", 1)
+	orig.Get("key1")
+	orig.Put("key2", 2)
+	s.Equal(2, orig.age)
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+
+	restored := NewLFU[string, int](1, WithDynamicAging())
+	s.NoError(restored.Restore(&buf))
+	s.Equal(2, restored.age)
+	s.Equal(3, freqOf(restored, "key2"))
+}
+
+// TestRestoreSmaller 测试恢复到容量更小的缓存时淘汰访问频率最低的元素
+func (s *SnapshotTestSuite) TestRestoreSmaller() {
+	orig := NewLFU[string, int](4)
+	for i := range 4 {
+		key := "key" + strconv.Itoa(i)
+		orig.Put(key, i)
+		for range i {
+			orig.Get(key)
+		}
+	}
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+
+	restored := NewLFU[string, int](2)
+	s.NoError(restored.Restore(&buf))
+	s.Equal([]string{"key3", "key2"}, restored.Keys())
+	s.Equal(4, freqOf(restored, "key3"))
+	s.Equal(2, restored.buckets.Size())
+}
+
+// TestRestoreCorrupted 测试快照损坏或类型不符时返回错误且缓存保持不变
+func (s *SnapshotTestSuite) TestRestoreCorrupted() {
+	orig := NewLFU[string, int](4)
+	orig.Put("key1", 1)
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+	data := buf.Bytes()
+
+	restored := NewLFU[string, int](4)
+	restored.Put("old", 0)
+
+	corrupted := bytes.Clone(data)
+	corrupted[len(corrupted)-1] ^= 0xff
+	s.ErrorIs(restored.Restore(bytes.NewReader(corrupted)), cache.ErrBadSnapshot)
+
+	// 用JSON读取gob写入的快照
+	restored.codec = cache.JSONCodec
+	s.ErrorIs(restored.Restore(bytes.NewReader(data)), cache.ErrBadSnapshot)
+	s.Equal([]string{"old"}, restored.Keys())
+}
+
+// TestSnapshot 运行所有LFU快照测试
+func TestSnapshot(t *testing.T) {
+	suite.Run(t, new(SnapshotTestSuite))
+}
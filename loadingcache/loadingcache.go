@@ -0,0 +1,338 @@
+// Package loadingcache 在LRU缓存之上集成持久化存储
+//
+// LoadingCache 在所有模式下都是读穿透的：未命中时从 Store 读取并写入缓存。
+// 写入时按 Mode 与存储同步：
+//
+//   - WriteThrough：先写存储再写缓存，两者始终一致；
+//   - WriteAround：写入直接写存储并使缓存中的旧值失效，下次读取时重新加载；
+//   - WriteBack：写入只更新缓存，被修改过的元素成为脏数据，在被淘汰后批量写回，
+//     或由 Flush、StartFlusher 主动写回。
+//
+// 回写模式下，被淘汰但尚未写回的脏数据仍可以通过 Get 读到，不会读到存储中的旧值；
+// 已过期的脏数据会在读取时先写回，再从存储加载。
+package loadingcache
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"algorithm/lru"
+	"context"
+	"sync"
+	"time"
+)
+
+// dirtyValue 是已离开缓存、尚未写回的脏数据
+type dirtyValue[V any] struct {
+	value   V
+	expired bool // 因过期离开缓存，只需写回，不再返回给读取者
+}
+
+// LoadingCache 是并发安全的、与持久化存储同步的LRU缓存
+type LoadingCache[K comparable, V any] struct {
+	mu        sync.Mutex
+	lru       *lru.LRU[K, V]
+	store     Store[K, V]
+	mode      Mode
+	batchSize int
+	clock     clock.Clock
+	onEvict   func(key K, value V, reason cache.EvictReason)
+	pending   cache.PendingEvictions[K, V] // 持锁期间离开缓存、尚未回调的元素
+	gen       uint64                       // 每次写入或删除加1，用于丢弃加载期间已过时的结果
+	dirty     map[K]struct{}               // 缓存中尚未写回的键
+	unflushed map[K]dirtyValue[V]          // 已离开缓存、尚未写回的脏数据
+	flushing  map[K]dirtyValue[V]          // 正在写回的脏数据，写回结束前仍可以读到
+	storeMu   sync.Mutex                   // 保证对存储的写入和删除与缓存的更新按相同的顺序执行
+}
+
+// New 创建以store为后端的缓存，容量通过 cache.WithCapacity 指定，
+// 同步方式通过 WithMode 指定
+//
+// 通过 cache.WithOnEvict 指定的回调在锁外执行。
+func New[K comparable, V any](store Store[K, V], opts ...cache.Option) *LoadingCache[K, V] {
+	o := cache.NewOptions(opts...)
+	c := &LoadingCache[K, V]{
+		lru:       lru.New[K, V](opts...),
+		store:     store,
+		mode:      modeOf(o),
+		batchSize: batchSize(o),
+		clock:     o.Clock,
+		onEvict:   cache.OnEvict[K, V](o),
+		dirty:     make(map[K]struct{}),
+		unflushed: make(map[K]dirtyValue[V]),
+		flushing:  make(map[K]dirtyValue[V]),
+	}
+	c.lru.OnEvict(c.evicted)
+
+	return c
+}
+
+// Get 返回key对应的值，未命中时从存储加载并写入缓存
+//
+// 存储中不存在时返回 ErrNotFound。
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	if val, ok := c.lookup(key, true); ok {
+		c.unlock()
+		return val, nil
+	}
+	expired := c.unwritten(key)
+	gen := c.gen
+	c.unlock()
+
+	if expired {
+		if err := c.flush(ctx, false); err != nil {
+			var zero V
+			return zero, err
+		}
+	}
+
+	val, err := c.store.Load(ctx, key)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	// 加载期间有新的写入时以缓存中的值为准，并且不缓存可能已过时的结果
+	if cur, ok := c.lookup(key, false); ok {
+		return cur, nil
+	}
+	if c.gen == gen {
+		c.lru.Put(key, val)
+	}
+
+	return val, nil
+}
+
+// lookup 依次在缓存、尚未写回和正在写回的脏数据中查找key
+func (c *LoadingCache[K, V]) lookup(key K, touch bool) (V, bool) {
+	get := c.lru.Peek
+	if touch {
+		get = c.lru.Get
+	}
+	if val, ok := get(key); ok {
+		return val, true
+	}
+
+	d, ok := c.unflushed[key]
+	if !ok {
+		d, ok = c.flushing[key]
+	}
+	return d.value, ok && !d.expired
+}
+
+// unwritten 判断key是否有已离开缓存、尚未写回完成的脏数据
+func (c *LoadingCache[K, V]) unwritten(key K) bool {
+	_, ok := c.unflushed[key]
+	if !ok {
+		_, ok = c.flushing[key]
+	}
+
+	return ok
+}
+
+// Put 写入元素，并按同步方式写入存储
+//
+// 写穿透和绕写模式下，同一时刻只有一个写入或删除在访问存储，
+// 存储和缓存中的结果总是来自同一次调用。回写模式下，被淘汰的脏数据累计到批量大小时在返回前写回，写回失败时返回错误，
+// 失败的数据会在之后重试。
+func (c *LoadingCache[K, V]) Put(ctx context.Context, key K, value V) error {
+	switch c.mode {
+	case WriteBack:
+		c.mu.Lock()
+		c.gen++
+		delete(c.unflushed, key)
+		c.dirty[key] = struct{}{}
+		c.lru.Put(key, value)
+		full := len(c.unflushed) >= c.batchSize
+		c.unlock()
+
+		if full {
+			return c.flush(ctx, false)
+		}
+		return nil
+
+	case WriteAround:
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+
+		if err := c.store.Store(ctx, key, value); err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		c.gen++
+		c.lru.Remove(key)
+		c.unlock()
+		return nil
+
+	default:
+		c.storeMu.Lock()
+		defer c.storeMu.Unlock()
+
+		if err := c.store.Store(ctx, key, value); err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		c.gen++
+		c.lru.Put(key, value)
+		c.unlock()
+		return nil
+	}
+}
+
+// Remove 从缓存和存储中删除key，回写模式下尚未写回的值被丢弃
+func (c *LoadingCache[K, V]) Remove(ctx context.Context, key K) error {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+
+	c.mu.Lock()
+	c.gen++
+	delete(c.dirty, key)
+	delete(c.unflushed, key)
+	c.lru.Remove(key)
+	c.unlock()
+
+	return c.store.Delete(ctx, key)
+}
+
+// Flush 把所有脏数据写回存储，写回失败的数据会在之后重试
+func (c *LoadingCache[K, V]) Flush(ctx context.Context) error {
+	return c.flush(ctx, true)
+}
+
+// StartFlusher 启动一个后台协程，每隔interval调用一次 Flush
+//
+// 写回失败的数据在下一次调用时重试。返回的stop函数用于停止该协程，可以重复调用；
+// 停止后应再调用一次 Flush 确保所有脏数据写回。
+func (c *LoadingCache[K, V]) StartFlusher(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-c.clock.After(interval):
+				c.Flush(context.Background())
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// Len 返回缓存中的元素数量
+func (c *LoadingCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Dirty 返回尚未写回存储的元素数量
+func (c *LoadingCache[K, V]) Dirty() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.dirty) + len(c.unflushed)
+}
+
+// flush 写回已离开缓存的脏数据，all为true时同时写回缓存中的脏数据
+func (c *LoadingCache[K, V]) flush(ctx context.Context, all bool) error {
+	c.storeMu.Lock()
+	defer c.storeMu.Unlock()
+
+	c.mu.Lock()
+	if all {
+		// 已过期的脏数据在清理时移入unflushed，之后不会再被读到
+		c.lru.PurgeExpired()
+	}
+	// 写回期间数据保存在flushing中，写回结束前 Get 仍能读到，不会从存储加载旧值
+	c.flushing, c.unflushed = c.unflushed, c.flushing
+	batch := make(map[K]V, len(c.flushing))
+	for key, d := range c.flushing {
+		batch[key] = d.value
+	}
+	if all {
+		for key := range c.dirty {
+			if val, ok := c.lru.Peek(key); ok {
+				batch[key] = val
+				c.flushing[key] = dirtyValue[V]{value: val}
+			}
+		}
+		clear(c.dirty)
+	}
+	c.unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	err := c.write(ctx, batch)
+	c.finish(batch)
+
+	return err
+}
+
+// write 把batch写入存储，成功写入的键从batch中删除
+func (c *LoadingCache[K, V]) write(ctx context.Context, batch map[K]V) error {
+	if bs, ok := c.store.(BatchStore[K, V]); ok {
+		if err := bs.StoreBatch(ctx, batch); err != nil {
+			return err
+		}
+		clear(batch)
+		return nil
+	}
+
+	for key, value := range batch {
+		if err := c.store.Store(ctx, key, value); err != nil {
+			return err
+		}
+		delete(batch, key)
+	}
+
+	return nil
+}
+
+// finish 结束一次写回，把batch中写回失败的数据放回unflushed，
+// 写回期间被再次修改的键除外
+func (c *LoadingCache[K, V]) finish(batch map[K]V) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for key, value := range batch {
+		if _, ok := c.dirty[key]; ok {
+			continue
+		}
+		if _, ok := c.unflushed[key]; ok {
+			continue
+		}
+		c.unflushed[key] = dirtyValue[V]{value, c.flushing[key].expired}
+	}
+	clear(c.flushing)
+}
+
+// evicted 是底层LRU的淘汰回调，在持锁期间执行
+func (c *LoadingCache[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	if reason == cache.EvictCapacity || reason == cache.EvictExpired {
+		if _, ok := c.dirty[key]; ok {
+			delete(c.dirty, key)
+			c.unflushed[key] = dirtyValue[V]{value, reason == cache.EvictExpired}
+		}
+	}
+
+	if c.onEvict != nil {
+		c.pending.Add(key, value, reason)
+	}
+}
+
+// unlock 释放锁，并在锁外执行持锁期间积攒的淘汰回调
+func (c *LoadingCache[K, V]) unlock() {
+	c.pending.Unlock(&c.mu, c.onEvict)
+}
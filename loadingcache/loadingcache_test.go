@@ -0,0 +1,441 @@
+package loadingcache
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"algorithm/lru"
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+var errStore = errors.New("store unavailable")
+
+// plainStore 隐藏 MemoryStore 的批量写入
+type plainStore[K comparable, V any] struct {
+	m *MemoryStore[K, V]
+}
+
+func (p plainStore[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return p.m.Load(ctx, key)
+}
+
+func (p plainStore[K, V]) Store(ctx context.Context, key K, value V) error {
+	return p.m.Store(ctx, key, value)
+}
+
+func (p plainStore[K, V]) Delete(ctx context.Context, key K) error {
+	return p.m.Delete(ctx, key)
+}
+
+// blockingStore 的 StoreBatch 在开始后阻塞，直到release被关闭
+type blockingStore[K comparable, V any] struct {
+	*MemoryStore[K, V]
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingStore[K, V]) StoreBatch(ctx context.Context, entries map[K]V) error {
+	b.once.Do(func() { close(b.started) })
+	<-b.release
+	return b.MemoryStore.StoreBatch(ctx, entries)
+}
+
+// delayStore 在写入和删除存储之后等待clock推进一段时间才返回，放大存储与缓存更新之间的时间窗口
+type delayStore[K comparable] struct {
+	*MemoryStore[K, int]
+	clock *clock.Manual
+}
+
+func (d delayStore[K]) Store(ctx context.Context, key K, value int) error {
+	defer d.wait(time.Duration(value%3+3) * time.Millisecond)
+	return d.MemoryStore.Store(ctx, key, value)
+}
+
+func (d delayStore[K]) Delete(ctx context.Context, key K) error {
+	defer d.wait(3 * time.Millisecond)
+	return d.MemoryStore.Delete(ctx, key)
+}
+
+func (d delayStore[K]) wait(delay time.Duration) {
+	<-d.clock.After(delay)
+}
+
+// drive 不断推进clk直到wg中的协程全部结束
+func drive(clk *clock.Manual, wg *sync.WaitGroup) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			clk.Advance(time.Millisecond)
+			runtime.Gosched()
+		}
+	}
+}
+
+// LoadingCacheTestSuite 是带持久化存储的缓存的测试套件
+type LoadingCacheTestSuite struct {
+	suite.Suite
+	ctx   context.Context
+	store *MemoryStore[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *LoadingCacheTestSuite) SetupTest() {
+	s.ctx = context.Background()
+	s.store = NewMemoryStore[string, int]()
+	for i := range 10 {
+		s.store.Store(s.ctx, "key"+strconv.Itoa(i), i)
+	}
+}
+
+// TestReadThrough 测试未命中时从存储加载，之后直接命中缓存
+func (s *LoadingCacheTestSuite) TestReadThrough() {
+	c := New[string, int](s.store, cache.WithCapacity(4), WithMode(WriteAround))
+
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(1, val)
+	val, err = c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(1, val)
+	loads, _, _, _ := s.store.Counts()
+	s.Equal(1, loads)
+
+	_, err = c.Get(s.ctx, "missing")
+	s.ErrorIs(err, ErrNotFound)
+	s.Equal(1, c.Len())
+}
+
+// TestWriteAround 测试绕写模式下写入直接写存储并使缓存失效
+func (s *LoadingCacheTestSuite) TestWriteAround() {
+	c := New[string, int](s.store, cache.WithCapacity(4), WithMode(WriteAround))
+	c.Get(s.ctx, "key1")
+
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.Equal(0, c.Len())
+	stored, _ := s.store.Get("key1")
+	s.Equal(100, stored)
+
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+}
+
+// TestWriteThrough 测试写穿透模式下先写存储再写缓存，存储失败时缓存不变
+func (s *LoadingCacheTestSuite) TestWriteThrough() {
+	c := New[string, int](s.store, cache.WithCapacity(4))
+
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	stored, _ := s.store.Get("key1")
+	s.Equal(100, stored)
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+	loads, _, _, _ := s.store.Counts()
+	s.Equal(0, loads)
+
+	s.store.Fail(errStore)
+	s.ErrorIs(c.Put(s.ctx, "key1", 200), errStore)
+	val, _ = c.Get(s.ctx, "key1")
+	s.Equal(100, val)
+	s.Equal(0, c.Dirty())
+}
+
+// TestWriteBack 测试回写模式下写入只更新缓存，被淘汰的脏数据仍可读到
+func (s *LoadingCacheTestSuite) TestWriteBack() {
+	c := New[string, int](s.store, cache.WithCapacity(2), WithMode(WriteBack))
+
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.NoError(c.Put(s.ctx, "key2", 200))
+	s.NoError(c.Put(s.ctx, "key3", 300))
+	_, stores, batches, _ := s.store.Counts()
+	s.Equal(10, stores)
+	s.Equal(0, batches)
+	s.Equal(3, c.Dirty())
+
+	// key1已被淘汰但尚未写回，读取时返回脏数据而不是存储中的旧值
+	stored, _ := s.store.Get("key1")
+	s.Equal(1, stored)
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+
+	s.NoError(c.Flush(s.ctx))
+	s.Equal(0, c.Dirty())
+	for key, want := range map[string]int{"key1": 100, "key2": 200, "key3": 300} {
+		stored, _ := s.store.Get(key)
+		s.Equal(want, stored)
+	}
+	_, _, batches, _ = s.store.Counts()
+	s.Equal(1, batches)
+
+	// 没有脏数据时不写存储
+	s.NoError(c.Flush(s.ctx))
+	_, _, batches, _ = s.store.Counts()
+	s.Equal(1, batches)
+}
+
+// TestWriteBackBatch 测试被淘汰的脏数据累计到批量大小时一次写回
+func (s *LoadingCacheTestSuite) TestWriteBackBatch() {
+	c := New[string, int](s.store, cache.WithCapacity(2), WithMode(WriteBack), WithBatchSize(3))
+
+	for i := range 5 {
+		s.NoError(c.Put(s.ctx, "new"+strconv.Itoa(i), i))
+	}
+
+	// 前3个被淘汰的脏数据一起写回，缓存中的2个仍是脏数据
+	_, _, batches, _ := s.store.Counts()
+	s.Equal(1, batches)
+	for i := range 3 {
+		stored, ok := s.store.Get("new" + strconv.Itoa(i))
+		s.True(ok)
+		s.Equal(i, stored)
+	}
+	_, ok := s.store.Get("new3")
+	s.False(ok)
+	s.Equal(2, c.Dirty())
+}
+
+// TestWriteBackPlainStore 测试存储不支持批量写入时逐个写回
+func (s *LoadingCacheTestSuite) TestWriteBackPlainStore() {
+	c := New[string, int](plainStore[string, int]{s.store}, cache.WithCapacity(4), WithMode(WriteBack))
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.NoError(c.Put(s.ctx, "key2", 200))
+
+	s.NoError(c.Flush(s.ctx))
+	_, stores, batches, _ := s.store.Counts()
+	s.Equal(12, stores)
+	s.Equal(0, batches)
+}
+
+// TestWriteBackRetry 测试写回失败时保留脏数据并在之后重试
+func (s *LoadingCacheTestSuite) TestWriteBackRetry() {
+	c := New[string, int](s.store, cache.WithCapacity(1), WithMode(WriteBack))
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.NoError(c.Put(s.ctx, "key2", 200))
+
+	s.store.Fail(errStore)
+	s.ErrorIs(c.Flush(s.ctx), errStore)
+	s.Equal(2, c.Dirty())
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+
+	// 失败期间再次写入的值覆盖失败的值
+	s.NoError(c.Put(s.ctx, "key2", 300))
+
+	s.store.Fail(nil)
+	s.NoError(c.Flush(s.ctx))
+	s.Equal(0, c.Dirty())
+	stored, _ := s.store.Get("key1")
+	s.Equal(100, stored)
+	stored, _ = s.store.Get("key2")
+	s.Equal(300, stored)
+}
+
+// TestWriteBackDuringFlush 测试正在写回的脏数据在写回结束前仍可读到，
+// 读取不会加载并缓存存储中的旧值
+func (s *LoadingCacheTestSuite) TestWriteBackDuringFlush() {
+	store := &blockingStore[string, int]{
+		MemoryStore: s.store,
+		started:     make(chan struct{}),
+		release:     make(chan struct{}),
+	}
+	c := New[string, int](store, cache.WithCapacity(1), WithMode(WriteBack))
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.NoError(c.Put(s.ctx, "key2", 200))
+
+	s.store.Fail(errStore)
+	done := make(chan error)
+	go func() {
+		done <- c.Flush(s.ctx)
+	}()
+
+	<-store.started
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+	loads, _, _, _ := s.store.Counts()
+	s.Equal(0, loads)
+
+	// 写回失败后数据放回队列，仍然读到新值
+	close(store.release)
+	s.ErrorIs(<-done, errStore)
+	val, _ = c.Get(s.ctx, "key1")
+	s.Equal(100, val)
+	s.Equal(2, c.Dirty())
+
+	s.store.Fail(nil)
+	s.NoError(c.Flush(s.ctx))
+	val, _ = c.Get(s.ctx, "key1")
+	s.Equal(100, val)
+	stored, _ := s.store.Get("key1")
+	s.Equal(100, stored)
+}
+
+// TestWriteBackExpired 测试已过期的脏数据在读取时先写回再从存储加载
+func (s *LoadingCacheTestSuite) TestWriteBackExpired() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	c := New[string, int](s.store, cache.WithCapacity(4), cache.WithClock(clk),
+		lru.WithDefaultTTL(time.Second), WithMode(WriteBack))
+	s.NoError(c.Put(s.ctx, "key1", 100))
+
+	clk.Advance(time.Second)
+	val, err := c.Get(s.ctx, "key1")
+	s.NoError(err)
+	s.Equal(100, val)
+	s.Equal(0, c.Dirty())
+	stored, _ := s.store.Get("key1")
+	s.Equal(100, stored)
+	loads, _, _, _ := s.store.Counts()
+	s.Equal(1, loads)
+
+	// Flush 写回缓存中已过期的脏数据
+	s.NoError(c.Put(s.ctx, "key2", 200))
+	clk.Advance(time.Second)
+	s.NoError(c.Flush(s.ctx))
+	stored, _ = s.store.Get("key2")
+	s.Equal(200, stored)
+}
+
+// TestRemove 测试删除同时作用于缓存和存储，并丢弃尚未写回的值
+func (s *LoadingCacheTestSuite) TestRemove() {
+	c := New[string, int](s.store, cache.WithCapacity(1), WithMode(WriteBack))
+	s.NoError(c.Put(s.ctx, "key1", 100))
+	s.NoError(c.Put(s.ctx, "key2", 200))
+
+	s.NoError(c.Remove(s.ctx, "key1"))
+	s.NoError(c.Remove(s.ctx, "key2"))
+	s.Equal(0, c.Dirty())
+	s.Equal(0, c.Len())
+
+	_, err := c.Get(s.ctx, "key1")
+	s.ErrorIs(err, ErrNotFound)
+	s.NoError(c.Flush(s.ctx))
+	_, ok := s.store.Get("key2")
+	s.False(ok)
+}
+
+// TestOnEvictOutsideLock 测试回调在锁外执行，回调中可以再次访问缓存
+func (s *LoadingCacheTestSuite) TestOnEvictOutsideLock() {
+	var reasons []cache.EvictReason
+	var c *LoadingCache[string, int]
+	c = New[string, int](s.store, cache.WithCapacity(1), cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		reasons = append(reasons, reason)
+		c.Len()
+	}))
+
+	c.Get(s.ctx, "key1")
+	c.Get(s.ctx, "key2")
+	s.NoError(c.Remove(s.ctx, "key2"))
+	s.Equal([]cache.EvictReason{cache.EvictCapacity, cache.EvictRemoved}, reasons)
+}
+
+// TestFlusher 测试后台协程定期写回脏数据
+func (s *LoadingCacheTestSuite) TestFlusher() {
+	synctest.Test(s.T(), func(t *testing.T) {
+		clk := clock.NewManual(time.Unix(0, 0))
+		c := New[string, int](s.store, cache.WithCapacity(4), cache.WithClock(clk), WithMode(WriteBack))
+		s.NoError(c.Put(s.ctx, "key1", 100))
+
+		stop := c.StartFlusher(time.Minute)
+		defer stop()
+		synctest.Wait()
+		s.Equal(1, c.Dirty())
+
+		clk.Advance(time.Minute)
+		synctest.Wait()
+		s.Equal(0, c.Dirty())
+		stored, _ := s.store.Get("key1")
+		s.Equal(100, stored)
+
+		stop()
+		stop()
+		synctest.Wait()
+	})
+}
+
+// TestConcurrentAccess 测试并发读写，需配合 -race 运行
+func (s *LoadingCacheTestSuite) TestConcurrentAccess() {
+	c := New[string, int](s.store, cache.WithCapacity(4), WithMode(WriteBack), WithBatchSize(2))
+
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Go(func() {
+			for i := range 200 {
+				key := "key" + strconv.Itoa((g+i)%10)
+				c.Get(s.ctx, key)
+				c.Put(s.ctx, key, i)
+				if i%50 == 0 {
+					c.Flush(s.ctx)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	s.NoError(c.Flush(s.ctx))
+	s.Equal(0, c.Dirty())
+	for i := range 10 {
+		key := "key" + strconv.Itoa(i)
+		stored, _ := s.store.Get(key)
+		val, err := c.Get(s.ctx, key)
+		s.NoError(err)
+		s.Equal(stored, val)
+	}
+}
+
+// TestConcurrentSameKey 测试对同一个键并发写入和删除后缓存与存储一致，需配合 -race 运行
+func (s *LoadingCacheTestSuite) TestConcurrentSameKey() {
+	for _, mode := range []Mode{WriteThrough, WriteAround} {
+		clk := clock.NewManual(time.Unix(0, 0))
+		c := New[string, int](delayStore[string]{s.store, clk}, cache.WithCapacity(4), WithMode(mode))
+		for i := range 200 {
+			var wg sync.WaitGroup
+			wg.Go(func() { c.Put(s.ctx, "key", i) })
+			wg.Go(func() { c.Put(s.ctx, "key", -i) })
+			if i%2 == 0 {
+				wg.Go(func() { c.Remove(s.ctx, "key") })
+			}
+			drive(clk, &wg)
+
+			stored, ok := s.store.Get("key")
+			val, err := c.Get(s.ctx, "key")
+			if ok {
+				s.Require().NoError(err, "%v round %d", mode, i)
+				s.Require().Equal(stored, val, "%v round %d", mode, i)
+			} else {
+				s.Require().ErrorIs(err, ErrNotFound, "%v round %d", mode, i)
+			}
+		}
+	}
+}
+
+// TestModeString 测试同步方式的名称
+func (s *LoadingCacheTestSuite) TestModeString() {
+	s.Equal("write-through", WriteThrough.String())
+	s.Equal("write-around", WriteAround.String())
+	s.Equal("write-back", WriteBack.String())
+	s.Equal("unknown", Mode(-1).String())
+}
+
+// TestLoadingCache 运行所有带持久化存储的缓存测试
+func TestLoadingCache(t *testing.T) {
+	suite.Run(t, new(LoadingCacheTestSuite))
+}
@@ -0,0 +1,64 @@
+package loadingcache
+
+import "algorithm/cache"
+
+// Mode 是缓存与存储之间的同步方式
+type Mode int
+
+const (
+	// WriteThrough 写入时先写存储，成功后再写缓存
+	WriteThrough Mode = iota
+	// WriteAround 写入绕过缓存直接写存储，并使缓存中的旧值失效，下次读取时重新加载
+	WriteAround
+	// WriteBack 写入只更新缓存并标记为脏数据，淘汰或定时刷新时再批量写回存储
+	WriteBack
+)
+
+func (m Mode) String() string {
+	switch m {
+	case WriteThrough:
+		return "write-through"
+	case WriteAround:
+		return "write-around"
+	case WriteBack:
+		return "write-back"
+	default:
+		return "unknown"
+	}
+}
+
+type (
+	modeKey      struct{}
+	batchSizeKey struct{}
+)
+
+// defaultBatchSize 是回写模式下默认的批量大小
+const defaultBatchSize = 64
+
+// WithMode 指定同步方式，默认为 WriteThrough
+func WithMode(m Mode) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(modeKey{}, m)
+	}
+}
+
+// WithBatchSize 指定回写模式下被淘汰的脏数据累计到多少条时写回存储，默认为64
+func WithBatchSize(n int) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(batchSizeKey{}, n)
+	}
+}
+
+func modeOf(o *cache.Options) Mode {
+	m, _ := o.Value(modeKey{}).(Mode)
+	return m
+}
+
+func batchSize(o *cache.Options) int {
+	n, ok := o.Value(batchSizeKey{}).(int)
+	if !ok || n < 1 {
+		return defaultBatchSize
+	}
+
+	return n
+}
@@ -0,0 +1,126 @@
+package loadingcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotFound 表示存储中不存在该键
+var ErrNotFound = errors.New("loadingcache: not found")
+
+// Store 是缓存背后的持久化存储
+type Store[K comparable, V any] interface {
+	// Load 读取key对应的值，不存在时返回 ErrNotFound
+	Load(ctx context.Context, key K) (V, error)
+	// Store 写入key对应的值
+	Store(ctx context.Context, key K, value V) error
+	// Delete 删除key，key不存在时不返回错误
+	Delete(ctx context.Context, key K) error
+}
+
+// BatchStore 是支持批量写入的存储，回写模式下优先使用批量写入
+type BatchStore[K comparable, V any] interface {
+	Store[K, V]
+	// StoreBatch 写入多个键值对
+	StoreBatch(ctx context.Context, entries map[K]V) error
+}
+
+// MemoryStore 是基于内存的 BatchStore，用于测试
+//
+// 它记录每种操作的调用次数，并可以通过 Fail 让之后的写入返回错误。
+type MemoryStore[K comparable, V any] struct {
+	mu      sync.Mutex
+	data    map[K]V
+	loads   int
+	stores  int
+	batches int
+	deletes int
+	failErr error
+}
+
+var _ BatchStore[string, int] = (*MemoryStore[string, int])(nil)
+
+// NewMemoryStore 创建一个空的内存存储
+func NewMemoryStore[K comparable, V any]() *MemoryStore[K, V] {
+	return &MemoryStore[K, V]{data: make(map[K]V)}
+}
+
+func (m *MemoryStore[K, V]) Load(ctx context.Context, key K) (V, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.loads++
+	val, ok := m.data[key]
+	if !ok {
+		return val, ErrNotFound
+	}
+
+	return val, nil
+}
+
+func (m *MemoryStore[K, V]) Store(ctx context.Context, key K, value V) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.stores++
+	m.data[key] = value
+
+	return nil
+}
+
+func (m *MemoryStore[K, V]) StoreBatch(ctx context.Context, entries map[K]V) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.batches++
+	for key, value := range entries {
+		m.data[key] = value
+	}
+
+	return nil
+}
+
+func (m *MemoryStore[K, V]) Delete(ctx context.Context, key K) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.deletes++
+	delete(m.data, key)
+
+	return nil
+}
+
+// Get 直接读取存储中的值，不计入 Load 的调用次数
+func (m *MemoryStore[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	val, ok := m.data[key]
+	return val, ok
+}
+
+// Fail 让之后的写入和删除返回err，传入nil恢复正常
+func (m *MemoryStore[K, V]) Fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.failErr = err
+}
+
+// Counts 返回 Load、Store、StoreBatch 和 Delete 成功执行的次数
+func (m *MemoryStore[K, V]) Counts() (loads, stores, batches, deletes int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.loads, m.stores, m.batches, m.deletes
+}
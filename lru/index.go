@@ -0,0 +1,110 @@
+package lru
+
+import "reflect"
+
+// tagIndex 是从标签到键集合的索引
+type tagIndex[K comparable] map[string]map[K]struct{}
+
+func (t tagIndex[K]) add(key K, tags []string) {
+	for _, tag := range tags {
+		keys, ok := t[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			t[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (t tagIndex[K]) remove(key K, tags []string) {
+	for _, tag := range tags {
+		keys := t[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(t, tag)
+		}
+	}
+}
+
+// trie 是按字节组织的前缀树，用于按前缀查找字符串键
+type trie[K comparable] struct {
+	children map[byte]*trie[K]
+	key      K
+	terminal bool // 是否有键在此结束
+}
+
+func (t *trie[K]) insert(s string, key K) {
+	node := t
+	for i := 0; i < len(s); i++ {
+		child, ok := node.children[s[i]]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[byte]*trie[K])
+			}
+			child = &trie[K]{}
+			node.children[s[i]] = child
+		}
+		node = child
+	}
+
+	node.key = key
+	node.terminal = true
+}
+
+// remove 删除键s，并删除因此不再通向任何键的节点
+func (t *trie[K]) remove(s string) {
+	if len(s) == 0 {
+		var zero K
+		t.key = zero
+		t.terminal = false
+		return
+	}
+
+	child, ok := t.children[s[0]]
+	if !ok {
+		return
+	}
+
+	child.remove(s[1:])
+	if !child.terminal && len(child.children) == 0 {
+		delete(t.children, s[0])
+	}
+}
+
+// collect 返回所有以prefix开头的键
+func (t *trie[K]) collect(prefix string) []K {
+	node := t
+	for i := 0; i < len(prefix) && node != nil; i++ {
+		node = node.children[prefix[i]]
+	}
+
+	var keys []K
+	var walk func(n *trie[K])
+	walk = func(n *trie[K]) {
+		if n.terminal {
+			keys = append(keys, n.key)
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	if node != nil {
+		walk(node)
+	}
+
+	return keys
+}
+
+// keyString 返回把键转换为字符串的函数，K的底层类型不是string时返回nil
+func keyString[K comparable]() func(K) string {
+	if reflect.TypeFor[K]().Kind() != reflect.String {
+		return nil
+	}
+
+	return func(key K) string {
+		if s, ok := any(key).(string); ok {
+			return s
+		}
+		return reflect.ValueOf(key).String()
+	}
+}
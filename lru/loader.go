@@ -0,0 +1,170 @@
+package lru
+
+import (
+	"algorithm/clock"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// call 是一次正在进行的加载
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// failure 是被缓存的加载错误
+type failure struct {
+	err      error
+	expireAt time.Time
+}
+
+// failedKey 是按失败时间排列的队列元素，用于清理过期的加载错误
+type failedKey[K comparable] struct {
+	key      K
+	expireAt time.Time
+}
+
+// loadable 是 flight 读写的并发安全缓存
+type loadable[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Peek(key K) (V, bool)
+	Put(key K, value V) error
+}
+
+// flight 合并同一个键的并发加载，并缓存加载错误
+type flight[K comparable, V any] struct {
+	cache       loadable[K, V]
+	clock       clock.Clock
+	mu          sync.Mutex
+	calls       map[K]*call[V]
+	failures    map[K]failure
+	order       []failedKey[K] // 负缓存时长固定，按失败时间排列即按过期时间排列
+	negativeTTL time.Duration
+}
+
+func newFlight[K comparable, V any](c loadable[K, V], clk clock.Clock, negativeTTL time.Duration) *flight[K, V] {
+	return &flight[K, V]{
+		cache:       c,
+		clock:       clk,
+		calls:       make(map[K]*call[V]),
+		failures:    make(map[K]failure),
+		negativeTTL: negativeTTL,
+	}
+}
+
+// GetOrLoad 返回key对应的值，未命中时调用loader加载并写入缓存
+//
+// 同一个键的并发加载会被合并为一次loader调用，所有等待者共享其结果。
+// loader使用第一个调用者的ctx（去掉取消信号）执行，因此某个调用者取消
+// 不会影响其他等待者；每个调用者在自己的ctx被取消时立即返回ctx.Err()。
+// 通过 WithNegativeTTL 设置后，加载错误会被缓存相应的时长，期间直接返回该错误。
+func (s *ShardedLRU[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	return s.flight.getOrLoad(ctx, key, loader)
+}
+
+func (f *flight[K, V]) getOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	if val, ok := f.cache.Get(key); ok {
+		return val, nil
+	}
+
+	var zero V
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	c, err := f.startLoad(ctx, key, loader)
+	if err != nil {
+		return zero, err
+	}
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// startLoad 返回key正在进行的加载，没有时发起一次新的加载
+func (f *flight[K, V]) startLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (*call[V], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.prune(f.clock.Now())
+	if fail, ok := f.failures[key]; ok {
+		return nil, fail.err
+	}
+
+	if c, ok := f.calls[key]; ok {
+		return c, nil
+	}
+
+	// 加载期间其他协程可能已经写入了该键
+	if val, ok := f.cache.Peek(key); ok {
+		c := &call[V]{done: make(chan struct{}), value: val}
+		close(c.done)
+		return c, nil
+	}
+
+	c := &call[V]{done: make(chan struct{})}
+	f.calls[key] = c
+	go f.load(context.WithoutCancel(ctx), key, loader, c)
+
+	return c, nil
+}
+
+func (f *flight[K, V]) load(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error), c *call[V]) {
+	val, err := safeLoad(ctx, key, loader)
+	loadErr := err
+	if err == nil {
+		// 写入缓存失败时loader本身是成功的，不缓存该错误
+		err = f.cache.Put(key, val)
+	}
+	if err != nil {
+		var zero V
+		val = zero
+	}
+
+	f.mu.Lock()
+	delete(f.calls, key)
+	if loadErr != nil && f.negativeTTL > 0 {
+		now := f.clock.Now()
+		f.prune(now)
+		expireAt := now.Add(f.negativeTTL)
+		f.failures[key] = failure{err: err, expireAt: expireAt}
+		f.order = append(f.order, failedKey[K]{key, expireAt})
+	}
+	f.mu.Unlock()
+
+	c.value, c.err = val, err
+	close(c.done)
+}
+
+// prune 删除在now时刻已经过期的加载错误，调用者需持有f.mu
+//
+// 同一个键过期后再次失败时队列中会有旧的元素，只有与当前记录的过期时间相同时才删除。
+func (f *flight[K, V]) prune(now time.Time) {
+	i := 0
+	for ; i < len(f.order) && !now.Before(f.order[i].expireAt); i++ {
+		k := f.order[i]
+		if fail, ok := f.failures[k.key]; ok && fail.expireAt.Equal(k.expireAt) {
+			delete(f.failures, k.key)
+		}
+	}
+
+	f.order = f.order[i:]
+}
+
+// safeLoad 调用loader，并把loader中的panic转换为错误
+func safeLoad[K comparable, V any](ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (val V, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("lru: loader panicked: %v", r)
+		}
+	}()
+
+	return loader(ctx, key)
+}
@@ -0,0 +1,219 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LoaderTestSuite 是GetOrLoad的测试套件
+type LoaderTestSuite struct {
+	suite.Suite
+	clock *clock.Manual
+	lru   *ShardedLRU[string, int]
+	calls atomic.Int32
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *LoaderTestSuite) SetupTest() {
+	s.clock = clock.NewManual(time.Unix(0, 0))
+	s.lru = NewShardedLRU[string, int](16, 4, cache.WithClock(s.clock), WithNegativeTTL(time.Second))
+	s.calls.Store(0)
+}
+
+func (s *LoaderTestSuite) loadLen(ctx context.Context, key string) (int, error) {
+	s.calls.Add(1)
+	return len(key), nil
+}
+
+// TestLoadAndCache 测试未命中时加载并缓存结果
+func (s *LoaderTestSuite) TestLoadAndCache() {
+	val, err := s.lru.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.NoError(err)
+	s.Equal(4, val)
+
+	val, err = s.lru.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.NoError(err)
+	s.Equal(4, val)
+	s.Equal(int32(1), s.calls.Load())
+
+	val, ok := s.lru.Get("key1")
+	s.True(ok)
+	s.Equal(4, val)
+}
+
+// TestCoalesce 测试并发加载同一个键时只调用一次loader
+func (s *LoaderTestSuite) TestCoalesce() {
+	synctest.Test(s.T(), func(t *testing.T) {
+		release := make(chan struct{})
+		loader := func(ctx context.Context, key string) (int, error) {
+			s.calls.Add(1)
+			<-release
+			return 42, nil
+		}
+
+		var wg sync.WaitGroup
+		results := make([]int, 10)
+		for i := range results {
+			wg.Go(func() {
+				val, err := s.lru.GetOrLoad(context.Background(), "key1", loader)
+				s.NoError(err)
+				results[i] = val
+			})
+		}
+
+		synctest.Wait()
+		close(release)
+		wg.Wait()
+
+		s.Equal(int32(1), s.calls.Load())
+		for _, val := range results {
+			s.Equal(42, val)
+		}
+	})
+}
+
+// TestNegativeTTL 测试加载错误在负缓存时长内被直接返回
+func (s *LoaderTestSuite) TestNegativeTTL() {
+	errLoad := errors.New("load failed")
+	failing := func(ctx context.Context, key string) (int, error) {
+		s.calls.Add(1)
+		return 0, errLoad
+	}
+
+	_, err := s.lru.GetOrLoad(context.Background(), "key1", failing)
+	s.ErrorIs(err, errLoad)
+	s.Equal(int32(1), s.calls.Load())
+
+	// 负缓存期间不再调用loader
+	_, err = s.lru.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.ErrorIs(err, errLoad)
+	s.Equal(int32(1), s.calls.Load())
+
+	// 过期后重新加载
+	s.clock.Advance(time.Second)
+	val, err := s.lru.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.NoError(err)
+	s.Equal(4, val)
+	s.Equal(int32(2), s.calls.Load())
+}
+
+// TestNegativeCachePruned 测试大量不同的键加载失败时，过期的错误会被清理
+func (s *LoaderTestSuite) TestNegativeCachePruned() {
+	errLoad := errors.New("load failed")
+	failing := func(ctx context.Context, key string) (int, error) {
+		return 0, errLoad
+	}
+
+	for round := range 10 {
+		for i := range 100 {
+			key := "key" + strconv.Itoa(round*100+i)
+			_, err := s.lru.GetOrLoad(context.Background(), key, failing)
+			s.ErrorIs(err, errLoad)
+		}
+		s.clock.Advance(time.Second)
+	}
+
+	// 所有错误都已过期，下一次失败时被清理
+	_, err := s.lru.GetOrLoad(context.Background(), "last", failing)
+	s.ErrorIs(err, errLoad)
+	s.lru.flight.mu.Lock()
+	defer s.lru.flight.mu.Unlock()
+	s.Len(s.lru.flight.failures, 1)
+	s.Len(s.lru.flight.order, 1)
+}
+
+// TestPutErrorNotCached 测试loader成功但写入缓存失败时返回零值，且不缓存该错误
+func (s *LoaderTestSuite) TestPutErrorNotCached() {
+	l := NewSyncLRU[string, int](1, cache.WithClock(s.clock), WithNegativeTTL(time.Second))
+	inner := l.shards[0].lru
+	inner.Put("pinned", 1)
+	inner.Pin("pinned")
+
+	val, err := l.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.ErrorIs(err, ErrPinned)
+	s.Zero(val)
+
+	inner.Unpin("pinned")
+	val, err = l.GetOrLoad(context.Background(), "key1", s.loadLen)
+	s.NoError(err)
+	s.Equal(4, val)
+	s.Equal(int32(2), s.calls.Load())
+}
+
+// TestErrorNotCached 测试未设置负缓存时每次都重新加载
+func (s *LoaderTestSuite) TestErrorNotCached() {
+	l := NewSyncLRU[string, int](4)
+	errLoad := errors.New("load failed")
+	failing := func(ctx context.Context, key string) (int, error) {
+		s.calls.Add(1)
+		return 0, errLoad
+	}
+
+	for range 3 {
+		_, err := l.GetOrLoad(context.Background(), "key1", failing)
+		s.ErrorIs(err, errLoad)
+	}
+	s.Equal(int32(3), s.calls.Load())
+	s.False(l.Contains("key1"))
+}
+
+// TestCancel 测试等待者的ctx取消后立即返回，加载继续完成
+func (s *LoaderTestSuite) TestCancel() {
+	synctest.Test(s.T(), func(t *testing.T) {
+		release := make(chan struct{})
+		loader := func(ctx context.Context, key string) (int, error) {
+			<-release
+			// 第一个调用者取消不影响加载
+			s.NoError(ctx.Err())
+			return 42, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			_, err := s.lru.GetOrLoad(ctx, "key1", loader)
+			done <- err
+		}()
+
+		synctest.Wait()
+		cancel()
+		s.ErrorIs(<-done, context.Canceled)
+
+		close(release)
+		synctest.Wait()
+
+		val, ok := s.lru.Get("key1")
+		s.True(ok)
+		s.Equal(42, val)
+	})
+
+	// 已取消的ctx直接返回
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.lru.GetOrLoad(ctx, "key2", s.loadLen)
+	s.ErrorIs(err, context.Canceled)
+	s.Equal(int32(0), s.calls.Load())
+}
+
+// TestLoaderPanic 测试loader中的panic被转换为错误
+func (s *LoaderTestSuite) TestLoaderPanic() {
+	_, err := s.lru.GetOrLoad(context.Background(), "key1", func(ctx context.Context, key string) (int, error) {
+		panic("boom")
+	})
+	s.ErrorContains(err, "boom")
+}
+
+// TestLoader 运行所有GetOrLoad测试
+func TestLoader(t *testing.T) {
+	suite.Run(t, new(LoaderTestSuite))
+}
@@ -0,0 +1,74 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"context"
+	"sync"
+)
+
+// LockedLRU 是用一把互斥锁保护的LRU，可以在多个协程中同时使用
+//
+// 除常用方法外，可以通过 Do 在锁内调用 LRU 的任意方法。与 ShardedLRU 不同，
+// 元素离开缓存时的回调在锁内执行，回调中不能再调用该 LockedLRU 的方法。
+type LockedLRU[K comparable, V any] struct {
+	mu     sync.Mutex
+	lru    *LRU[K, V]
+	flight *flight[K, V]
+}
+
+// NewLockedLRU 创建容量为capacity、用一把互斥锁保护的LRU
+func NewLockedLRU[K comparable, V any](capacity int, opts ...cache.Option) *LockedLRU[K, V] {
+	o := cache.NewOptions(opts...)
+	l := &LockedLRU[K, V]{lru: NewLRU[K, V](capacity, opts...)}
+	l.flight = newFlight[K, V](l, o.Clock, negativeTTL(o))
+
+	return l
+}
+
+// Do 在锁内调用fn，fn返回后不能再使用传入的LRU
+func (l *LockedLRU[K, V]) Do(fn func(lru *LRU[K, V])) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	fn(l.lru)
+}
+
+func (l *LockedLRU[K, V]) Get(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Get(key)
+}
+
+func (l *LockedLRU[K, V]) Peek(key K) (V, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Peek(key)
+}
+
+func (l *LockedLRU[K, V]) Put(key K, value V) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Put(key, value)
+}
+
+func (l *LockedLRU[K, V]) Remove(key K) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Remove(key)
+}
+
+func (l *LockedLRU[K, V]) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.lru.Len()
+}
+
+// GetOrLoad 返回key对应的值，未命中时调用loader加载并写入缓存，行为与 ShardedLRU.GetOrLoad 相同
+func (l *LockedLRU[K, V]) GetOrLoad(ctx context.Context, key K, loader func(ctx context.Context, key K) (V, error)) (V, error) {
+	return l.flight.getOrLoad(ctx, key, loader)
+}
@@ -0,0 +1,113 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// LockedLRUTestSuite 是LockedLRU的测试套件
+type LockedLRUTestSuite struct {
+	suite.Suite
+	clock *clock.Manual
+	lru   *LockedLRU[string, int]
+	calls atomic.Int32
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *LockedLRUTestSuite) SetupTest() {
+	s.clock = clock.NewManual(time.Unix(0, 0))
+	s.lru = NewLockedLRU[string, int](2, cache.WithClock(s.clock), WithNegativeTTL(time.Second))
+	s.calls.Store(0)
+}
+
+// TestPutGetRemove 测试基本的读写和删除
+func (s *LockedLRUTestSuite) TestPutGetRemove() {
+	s.NoError(s.lru.Put("key1", 1))
+	s.NoError(s.lru.Put("key2", 2))
+	s.lru.Get("key1")
+	s.NoError(s.lru.Put("key3", 3))
+
+	_, ok := s.lru.Peek("key2")
+	s.False(ok)
+	val, ok := s.lru.Get("key1")
+	s.True(ok)
+	s.Equal(1, val)
+
+	s.True(s.lru.Remove("key1"))
+	s.Equal(1, s.lru.Len())
+}
+
+// TestDo 测试在锁内调用LRU的其他方法
+func (s *LockedLRUTestSuite) TestDo() {
+	s.lru.Do(func(lru *LRU[string, int]) {
+		s.NoError(lru.PutWithTTL("key1", 1, time.Second))
+	})
+
+	s.clock.Advance(time.Second)
+	_, ok := s.lru.Get("key1")
+	s.False(ok)
+}
+
+// TestGetOrLoad 测试并发加载同一个键时只调用一次loader，结果写入缓存
+func (s *LockedLRUTestSuite) TestGetOrLoad() {
+	synctest.Test(s.T(), func(t *testing.T) {
+		release := make(chan struct{})
+		loader := func(ctx context.Context, key string) (int, error) {
+			s.calls.Add(1)
+			<-release
+			return 42, nil
+		}
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				val, err := s.lru.GetOrLoad(context.Background(), "key1", loader)
+				s.NoError(err)
+				s.Equal(42, val)
+			})
+		}
+
+		synctest.Wait()
+		close(release)
+		wg.Wait()
+
+		s.Equal(int32(1), s.calls.Load())
+		val, ok := s.lru.Peek("key1")
+		s.True(ok)
+		s.Equal(42, val)
+	})
+}
+
+// TestNegativeTTL 测试加载错误在负缓存时长内被直接返回
+func (s *LockedLRUTestSuite) TestNegativeTTL() {
+	errLoad := errors.New("load failed")
+	failing := func(ctx context.Context, key string) (int, error) {
+		s.calls.Add(1)
+		return 0, errLoad
+	}
+
+	_, err := s.lru.GetOrLoad(context.Background(), "key1", failing)
+	s.ErrorIs(err, errLoad)
+	_, err = s.lru.GetOrLoad(context.Background(), "key1", failing)
+	s.ErrorIs(err, errLoad)
+	s.Equal(int32(1), s.calls.Load())
+
+	s.clock.Advance(time.Second)
+	_, err = s.lru.GetOrLoad(context.Background(), "key1", failing)
+	s.ErrorIs(err, errLoad)
+	s.Equal(int32(2), s.calls.Load())
+}
+
+// TestLockedLRU 运行所有LockedLRU测试
+func TestLockedLRU(t *testing.T) {
+	suite.Run(t, new(LockedLRUTestSuite))
+}
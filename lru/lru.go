@@ -1,61 +1,177 @@
 package lru
 
 import (
+	"algorithm/cache"
+	"algorithm/clock"
 	"algorithm/double_linked_list"
+	"iter"
+	"time"
 )
 
 type entry[K comparable, V any] struct {
-	key   K
-	value V
+	key      K
+	value    V
+	expireAt time.Time // 零值表示永不过期
+	cost     int64     // 按权重计算容量时的开销
+	pins     int       // 固定的引用计数，大于0时不会因容量不足被淘汰
+	tags     []string  // 去重并排序后的标签
 }
 
+// LRU 最近最少使用缓存，不是并发安全的
+//
+// 过期元素在访问时被删除，也可以调用 PurgeExpired 主动清理。LRU 本身没有后台清理协程，
+// 需要定期清理时使用 ShardedLRU 或 NewSyncLRU，并调用 StartJanitor。
 type LRU[K comparable, V any] struct {
-	cache    map[K]*double_linked_list.DNode[entry[K, V]]
-	list     *double_linked_list.DoubleLinkedList[entry[K, V]]
-	capacity int
+	cache      map[K]*double_linked_list.DNode[entry[K, V]]
+	list       *double_linked_list.DoubleLinkedList[entry[K, V]]
+	capacity   int
+	clock      clock.Clock
+	defaultTTL time.Duration
+	onEvict    func(key K, value V, reason cache.EvictReason)
+	stats      cache.StatsCounter
+	weigher    func(key K, value V) int64 // 非nil时按总开销而不是元素数量限制容量
+	cost       int64
+	maxCost    int64
+	pinned     int   // 被固定的元素数量
+	pinnedCost int64 // 被固定的元素的总开销
+	tags       tagIndex[K]
+	prefixes   *trie[K]           // 前缀索引，未启用时为nil
+	keyString  func(key K) string // 键的底层类型不是string时为nil
+	codec      cache.Codec
 }
 
-func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
-	return &LRU[K, V]{
-		cache:    make(map[K]*double_linked_list.DNode[entry[K, V]]),
-		list:     double_linked_list.NewDoubleLinkedList[entry[K, V]](),
-		capacity: capacity,
+var _ cache.Cache[string, int] = (*LRU[string, int])(nil)
+
+// New 根据构造选项创建LRU缓存，容量通过 cache.WithCapacity 指定
+func New[K comparable, V any](opts ...cache.Option) *LRU[K, V] {
+	o := cache.NewOptions(opts...)
+
+	l := &LRU[K, V]{
+		cache:      make(map[K]*double_linked_list.DNode[entry[K, V]]),
+		list:       double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		capacity:   o.Capacity,
+		clock:      o.Clock,
+		defaultTTL: defaultTTL(o),
+		onEvict:    cache.OnEvict[K, V](o),
+		tags:       make(tagIndex[K]),
+		keyString:  keyString[K](),
+		codec:      o.Codec,
+	}
+	if l.keyString != nil && prefixIndex(o) {
+		l.prefixes = &trie[K]{}
 	}
+
+	return l
+}
+
+// NewLRU 创建容量为capacity的LRU缓存
+func NewLRU[K comparable, V any](capacity int, opts ...cache.Option) *LRU[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
 }
 
+// Get 返回key对应的值，已过期的元素视为不存在并被删除
 func (l *LRU[K, V]) Get(key K) (V, bool) {
 	var zero V
 	node, ok := l.cache[key]
 	if !ok {
+		l.stats.Miss()
+		return zero, false
+	}
+
+	if l.expired(node.Val, l.clock.Now()) {
+		l.removeNode(node, cache.EvictExpired)
+		l.stats.Miss()
 		return zero, false
 	}
 
 	err := l.list.MoveToTail(node)
 	if err != nil {
+		l.stats.Miss()
 		return zero, false
 	}
 
+	l.stats.Hit()
 	return node.Val.value, true
 }
 
-func (l *LRU[K, V]) Put(key K, value V) {
+// Peek 返回key对应的值，不改变元素的访问顺序
+func (l *LRU[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	node, ok := l.cache[key]
+	if !ok || l.expired(node.Val, l.clock.Now()) {
+		return zero, false
+	}
+
+	return node.Val.value, true
+}
+
+// Contains 判断key是否存在且未过期，不改变元素的访问顺序
+func (l *LRU[K, V]) Contains(key K) bool {
+	_, ok := l.Peek(key)
+	return ok
+}
+
+// Put 写入元素，过期时间为默认TTL（未设置时永不过期）
+func (l *LRU[K, V]) Put(key K, value V) error {
+	return l.PutWithTTL(key, value, l.defaultTTL)
+}
+
+// PutWithTTL 写入元素，并在ttl之后过期；ttl<=0表示永不过期
+//
+// 按权重计算容量时，开销超过上限的元素会被拒绝并返回 ErrTooLarge；被固定的元素
+// 占满容量、无法为新元素腾出空间时返回 ErrPinned。两种情况下缓存都保持不变。
+func (l *LRU[K, V]) PutWithTTL(key K, value V, ttl time.Duration) error {
+	return l.put(key, value, ttl, nil, false)
+}
+
+// put 写入元素，retag为true时把元素的标签替换为tags，否则保留已有的标签
+func (l *LRU[K, V]) put(key K, value V, ttl time.Duration, tags []string, retag bool) error {
+	cost, err := l.weigh(key, value)
+	if err != nil {
+		return err
+	}
+
 	node, ok := l.cache[key]
+	if !l.fits(node, cost) {
+		return ErrPinned
+	}
+
+	var expireAt time.Time
+	if ttl > 0 {
+		expireAt = l.clock.Now().Add(ttl)
+	}
+
+	if retag {
+		tags = normalizeTags(tags)
+	}
+
+	l.cost += cost
 	if ok {
-		node.Val = entry[K, V]{key, value}
+		old := node.Val
+		if !retag {
+			tags = old.tags
+		}
+		node.Val = entry[K, V]{key, value, expireAt, cost, old.pins, tags}
+		if old.pins > 0 {
+			l.pinnedCost += cost - old.cost
+		}
+		if retag {
+			l.tags.remove(key, old.tags)
+			l.tags.add(key, tags)
+		}
 		l.list.MoveToTail(node)
-		return
+		l.evicted(old, cache.EvictReplaced)
+		l.evictOverflow()
+		return nil
 	}
 
-	newNode := l.list.Append(entry[K, V]{key, value})
+	newNode := l.list.Append(entry[K, V]{key, value, expireAt, cost, 0, tags})
 	l.cache[key] = newNode
+	l.index(newNode.Val)
+	l.stats.Insert()
+	l.evictOverflow()
 
-	if l.list.Size() > l.capacity {
-		removed, err := l.list.RemoveHead()
-		if err == nil {
-			key := removed.key
-			delete(l.cache, key)
-		}
-	}
+	return nil
 }
 
 func (l *LRU[K, V]) Remove(key K) bool {
@@ -64,14 +180,149 @@ func (l *LRU[K, V]) Remove(key K) bool {
 		return false
 	}
 
+	return l.removeNode(node, cache.EvictRemoved)
+}
+
+// PurgeExpired 删除所有已过期的元素，返回删除的数量
+func (l *LRU[K, V]) PurgeExpired() int {
+	now := l.clock.Now()
+	purged := 0
+	for node := range l.list.Nodes() {
+		if l.expired(node.Val, now) && l.removeNode(node, cache.EvictExpired) {
+			purged++
+		}
+	}
+
+	return purged
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+func (l *LRU[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	l.onEvict = fn
+}
+
+// Stats 返回统计信息，只有 Get 计入命中或未命中，命中已过期的元素记为未命中
+func (l *LRU[K, V]) Stats() cache.Stats {
+	return l.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (l *LRU[K, V]) ResetStats() {
+	l.stats.Reset()
+}
+
+// Size 返回元素数量，已过期但尚未被清理的元素也计算在内
+func (l *LRU[K, V]) Size() int {
+	return l.list.Size()
+}
+
+// Len 与 Size 相同
+func (l *LRU[K, V]) Len() int {
+	return l.list.Size()
+}
+
+// Keys 按从最近到最久访问的顺序返回所有未过期的键
+func (l *LRU[K, V]) Keys() []K {
+	keys := make([]K, 0, l.list.Size())
+	for key := range l.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// All 返回按从最近到最久访问的顺序遍历所有未过期元素的迭代器，遍历不改变访问顺序
+func (l *LRU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		now := l.clock.Now()
+		for node := range l.list.Backward() {
+			if !l.expired(node.Val, now) && !yield(node.Val.key, node.Val.value) {
+				return
+			}
+		}
+	}
+}
+
+// Clear 删除所有元素，每个元素都会以 cache.EvictRemoved 触发回调
+func (l *LRU[K, V]) Clear() {
+	old := l.list
+	l.cache = make(map[K]*double_linked_list.DNode[entry[K, V]])
+	l.list = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	l.pinned = 0
+	l.pinnedCost = 0
+	l.resetIndex()
+
+	for node := range old.Nodes() {
+		l.evicted(node.Val, cache.EvictRemoved)
+	}
+}
+
+// Resize 修改容量，容量缩小时立即从最久未访问的一端淘汰元素，返回淘汰的数量
+//
+// 按权重计算容量时，capacity为新的开销上限。被固定的元素不会被淘汰，
+// 因此缓存可能暂时超出新的容量，直到这些元素被取消固定。
+func (l *LRU[K, V]) Resize(capacity int) int {
+	if l.weigher != nil {
+		l.maxCost = int64(capacity)
+	} else {
+		l.capacity = capacity
+	}
+
+	return l.evictOverflow()
+}
+
+// overflow 判断是否超出容量
+func (l *LRU[K, V]) overflow() bool {
+	if l.weigher != nil {
+		return l.cost > l.maxCost
+	}
+
+	return l.list.Size() > l.capacity
+}
+
+// evictOverflow 从链表头部跳过被固定的元素淘汰，直到不超过容量，返回淘汰的数量
+func (l *LRU[K, V]) evictOverflow() int {
+	evicted := 0
+	for node := range l.list.Nodes() {
+		if !l.overflow() {
+			break
+		}
+		if node.Val.pins > 0 {
+			continue
+		}
+
+		if l.removeNode(node, cache.EvictCapacity) {
+			evicted++
+		}
+	}
+
+	return evicted
+}
+
+func (l *LRU[K, V]) removeNode(node *double_linked_list.DNode[entry[K, V]], reason cache.EvictReason) bool {
+	e := node.Val
 	if err := l.list.Remove(node); err != nil {
 		return false
 	}
 
-	delete(l.cache, key)
+	delete(l.cache, e.key)
+	l.unindex(e)
+	if e.pins > 0 {
+		l.pinned--
+		l.pinnedCost -= e.cost
+	}
+	l.evicted(e, reason)
 	return true
 }
 
-func (l *LRU[K, V]) Size() int {
-	return l.list.Size()
+func (l *LRU[K, V]) evicted(e entry[K, V], reason cache.EvictReason) {
+	l.cost -= e.cost
+	l.stats.Evict(reason)
+	if l.onEvict != nil {
+		l.onEvict(e.key, e.value, reason)
+	}
+}
+
+func (l *LRU[K, V]) expired(e entry[K, V], now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
 }
@@ -1,7 +1,12 @@
 package lru
 
 import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/clock"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -242,7 +247,283 @@ func (s *LRUTestSuite) TestComplexOperations() {
 	s.False(ok)
 }
 
+// TestPutWithTTL 测试元素过期后被视为不存在
+func (s *LRUTestSuite) TestPutWithTTL() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](3, cache.WithClock(clk))
+
+	lru.PutWithTTL("key1", 1, time.Second)
+	lru.PutWithTTL("key2", 2, 0)
+
+	clk.Advance(999 * time.Millisecond)
+	val, ok := lru.Get("key1")
+	s.True(ok)
+	s.Equal(1, val)
+
+	// 到期后Get返回未命中，并删除该元素
+	clk.Advance(time.Millisecond)
+	val, ok = lru.Get("key1")
+	s.False(ok)
+	s.Equal(0, val)
+	s.Equal(1, lru.Size())
+
+	// ttl<=0 永不过期
+	clk.Advance(time.Hour)
+	val, ok = lru.Get("key2")
+	s.True(ok)
+	s.Equal(2, val)
+}
+
+// TestDefaultTTL 测试默认过期时间以及覆盖写入时刷新过期时间
+func (s *LRUTestSuite) TestDefaultTTL() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](3, cache.WithClock(clk), WithDefaultTTL(time.Minute))
+
+	lru.Put("key1", 1)
+	clk.Advance(30 * time.Second)
+	lru.Put("key1", 10)
+
+	clk.Advance(45 * time.Second)
+	val, ok := lru.Get("key1")
+	s.True(ok)
+	s.Equal(10, val)
+
+	clk.Advance(15 * time.Second)
+	_, ok = lru.Get("key1")
+	s.False(ok)
+
+	// 显式指定的TTL优先于默认值
+	lru.PutWithTTL("key2", 2, 0)
+	clk.Advance(time.Hour)
+	_, ok = lru.Get("key2")
+	s.True(ok)
+}
+
+// TestPurgeExpired 测试批量清理过期元素
+func (s *LRUTestSuite) TestPurgeExpired() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](5, cache.WithClock(clk))
+
+	lru.PutWithTTL("key1", 1, time.Second)
+	lru.PutWithTTL("key2", 2, 2*time.Second)
+	lru.PutWithTTL("key3", 3, time.Second)
+	lru.Put("key4", 4)
+
+	s.Equal(0, lru.PurgeExpired())
+
+	clk.Advance(time.Second)
+	s.Equal(2, lru.PurgeExpired())
+	s.Equal(2, lru.Size())
+
+	clk.Advance(time.Second)
+	s.Equal(1, lru.PurgeExpired())
+	s.Equal(1, lru.Size())
+
+	val, ok := lru.Get("key4")
+	s.True(ok)
+	s.Equal(4, val)
+}
+
+// TestOnEvict 测试各种原因离开缓存时触发回调
+func (s *LRUTestSuite) TestOnEvict() {
+	type evicted struct {
+		key    string
+		value  int
+		reason cache.EvictReason
+	}
+	var got []evicted
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](2, cache.WithClock(clk))
+	lru.OnEvict(func(key string, value int, reason cache.EvictReason) {
+		got = append(got, evicted{key, value, reason})
+	})
+
+	lru.Put("key1", 1)
+	lru.Put("key1", 10)
+	lru.Put("key2", 2)
+	lru.Put("key3", 3)
+	lru.Remove("key2")
+	lru.PutWithTTL("key4", 4, time.Second)
+	clk.Advance(time.Second)
+	lru.Get("key4")
+	lru.PutWithTTL("key5", 5, time.Second)
+	clk.Advance(time.Second)
+	lru.PurgeExpired()
+
+	s.Equal([]evicted{
+		{"key1", 1, cache.EvictReplaced},
+		{"key1", 10, cache.EvictCapacity},
+		{"key2", 2, cache.EvictRemoved},
+		{"key4", 4, cache.EvictExpired},
+		{"key5", 5, cache.EvictExpired},
+	}, got)
+
+	// 取消回调
+	lru.OnEvict(nil)
+	lru.Remove("key3")
+	s.Len(got, 5)
+}
+
+// TestPeekKeepsOrder 测试Peek不改变访问顺序
+func (s *LRUTestSuite) TestPeekKeepsOrder() {
+	s.lru.Put("key1", 1)
+	s.lru.Put("key2", 2)
+	s.lru.Put("key3", 3)
+
+	val, ok := s.lru.Peek("key1")
+	s.True(ok)
+	s.Equal(1, val)
+	s.True(s.lru.Contains("key1"))
+
+	// key1仍然是最久未访问的元素
+	s.lru.Put("key4", 4)
+	s.False(s.lru.Contains("key1"))
+}
+
+// TestKeysOrder 测试Keys按从最近到最久访问的顺序返回
+func (s *LRUTestSuite) TestKeysOrder() {
+	s.lru.Put("key1", 1)
+	s.lru.Put("key2", 2)
+	s.lru.Put("key3", 3)
+	s.lru.Get("key1")
+
+	s.Equal([]string{"key1", "key3", "key2"}, s.lru.Keys())
+}
+
+// TestAll 测试All按从最近到最久访问的顺序遍历，且不改变访问顺序
+func (s *LRUTestSuite) TestAll() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](4, cache.WithClock(clk))
+	lru.Put("key1", 1)
+	lru.Put("key2", 2)
+	lru.PutWithTTL("key3", 3, time.Second)
+	lru.Put("key4", 4)
+	lru.Get("key1")
+	clk.Advance(time.Second)
+
+	var keys []string
+	var values []int
+	for key, value := range lru.All() {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	s.Equal([]string{"key1", "key4", "key2"}, keys)
+	s.Equal([]int{1, 4, 2}, values)
+
+	// 遍历不改变访问顺序
+	s.Equal(keys, lru.Keys())
+
+	// 提前停止遍历
+	keys = nil
+	for key := range lru.All() {
+		keys = append(keys, key)
+		break
+	}
+	s.Equal([]string{"key1"}, keys)
+}
+
+// TestAllRemove 测试遍历期间删除当前元素
+func (s *LRUTestSuite) TestAllRemove() {
+	for i := range 3 {
+		s.lru.Put(strconv.Itoa(i), i)
+	}
+
+	var keys []string
+	for key, value := range s.lru.All() {
+		keys = append(keys, key)
+		if value%2 == 0 {
+			s.True(s.lru.Remove(key))
+		}
+	}
+	s.Equal([]string{"2", "1", "0"}, keys)
+	s.Equal([]string{"1"}, s.lru.Keys())
+}
+
+// TestResize 测试缩小容量时淘汰最久未访问的元素
+func (s *LRUTestSuite) TestResize() {
+	s.lru.Put("key1", 1)
+	s.lru.Put("key2", 2)
+	s.lru.Put("key3", 3)
+	s.lru.Get("key1")
+
+	s.Equal(2, s.lru.Resize(1))
+	s.Equal([]string{"key1"}, s.lru.Keys())
+}
+
+// TestResizeHooks 测试Resize淘汰元素时触发回调，扩大容量后可以容纳更多元素
+func (s *LRUTestSuite) TestResizeHooks() {
+	var evicted []string
+	lru := NewLRU[string, int](4, cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		s.Equal(cache.EvictCapacity, reason)
+		evicted = append(evicted, key)
+	}))
+	lru.Put("key1", 1)
+	lru.Put("key2", 2)
+	lru.Put("key3", 3)
+	lru.Put("key4", 4)
+	lru.Get("key2")
+
+	s.Equal(3, lru.Resize(1))
+	s.Equal([]string{"key1", "key3", "key4"}, evicted)
+	s.Equal([]string{"key2"}, lru.Keys())
+
+	// 扩大容量不淘汰元素
+	evicted = nil
+	s.Equal(0, lru.Resize(3))
+	lru.Put("key5", 5)
+	lru.Put("key6", 6)
+	s.Empty(evicted)
+	s.Equal(3, lru.Len())
+
+	// 容量为0时淘汰所有元素
+	s.Equal(3, lru.Resize(0))
+	s.Equal(0, lru.Len())
+	lru.Put("key7", 7)
+	s.Equal(0, lru.Len())
+}
+
+// TestStats 测试命中、未命中、写入、更新和淘汰的统计
+func (s *LRUTestSuite) TestStats() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	lru := NewLRU[string, int](2, cache.WithClock(clk))
+
+	lru.Put("key1", 1)
+	lru.Put("key1", 10)
+	lru.Put("key2", 2)
+	lru.Put("key3", 3)
+	lru.Get("key3")
+	lru.Get("key1")
+	lru.Peek("key2")
+	lru.PutWithTTL("key4", 4, time.Second)
+	clk.Advance(time.Second)
+	lru.Get("key4")
+
+	st := lru.Stats()
+	s.Equal(uint64(1), st.Hits)
+	s.Equal(uint64(2), st.Misses)
+	s.Equal(uint64(4), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(uint64(2), st.Evictions[cache.EvictCapacity])
+	s.Equal(uint64(1), st.Evictions[cache.EvictExpired])
+	s.Equal(lru.Len(), st.Size)
+
+	lru.ResetStats()
+	lru.Remove("key3")
+	st = lru.Stats()
+	s.Zero(st.Hits)
+	s.Zero(st.Insertions)
+	s.Equal(uint64(1), st.Evictions[cache.EvictRemoved])
+	s.Equal(0, st.Size)
+}
+
 // TestLRU 运行所有LRU测试
 func TestLRU(t *testing.T) {
 	suite.Run(t, new(LRUTestSuite))
 }
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
@@ -0,0 +1,49 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"time"
+)
+
+type (
+	defaultTTLKey  struct{}
+	negativeTTLKey struct{}
+	prefixIndexKey struct{}
+)
+
+// WithDefaultTTL 指定 Put 写入元素的默认过期时间，默认永不过期
+func WithDefaultTTL(ttl time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(defaultTTLKey{}, ttl)
+	}
+}
+
+func defaultTTL(o *cache.Options) time.Duration {
+	ttl, _ := o.Value(defaultTTLKey{}).(time.Duration)
+	return ttl
+}
+
+// WithNegativeTTL 指定 GetOrLoad 缓存加载错误的时长，默认不缓存
+func WithNegativeTTL(ttl time.Duration) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(negativeTTLKey{}, ttl)
+	}
+}
+
+func negativeTTL(o *cache.Options) time.Duration {
+	ttl, _ := o.Value(negativeTTLKey{}).(time.Duration)
+	return ttl
+}
+
+// WithPrefixIndex 为底层类型为string的键维护前缀树，使 InvalidatePrefix 只访问
+// 匹配的键，代价是每次写入和删除都要更新前缀树；其他类型的键忽略该选项
+func WithPrefixIndex() cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(prefixIndexKey{}, true)
+	}
+}
+
+func prefixIndex(o *cache.Options) bool {
+	enabled, _ := o.Value(prefixIndexKey{}).(bool)
+	return enabled
+}
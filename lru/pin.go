@@ -0,0 +1,76 @@
+package lru
+
+import (
+	"algorithm/double_linked_list"
+	"errors"
+)
+
+// ErrPinned 表示被固定的元素占满了容量，无法为新元素腾出空间
+var ErrPinned = errors.New("lru: not enough unpinned entries to evict")
+
+// Pin 固定key对应的元素，被固定的元素不会因容量不足被淘汰，返回元素是否存在
+//
+// 固定使用引用计数，每次 Pin 都需要一次对应的 Unpin。固定只豁免容量淘汰，
+// 元素仍然会过期，也可以被 Remove 或 Clear 删除，删除后引用计数随之清零。
+func (l *LRU[K, V]) Pin(key K) bool {
+	node, ok := l.cache[key]
+	if !ok || l.expired(node.Val, l.clock.Now()) {
+		return false
+	}
+
+	if node.Val.pins == 0 {
+		l.pinned++
+		l.pinnedCost += node.Val.cost
+	}
+	node.Val.pins++
+
+	return true
+}
+
+// Unpin 将key对应元素的引用计数减1，返回元素是否存在且处于固定状态
+//
+// 引用计数归零后元素恢复为普通元素；此时如果缓存超出容量（例如固定期间调用了
+// Resize），会立即从最久未访问的一端淘汰元素。
+func (l *LRU[K, V]) Unpin(key K) bool {
+	node, ok := l.cache[key]
+	if !ok || node.Val.pins == 0 {
+		return false
+	}
+
+	node.Val.pins--
+	if node.Val.pins == 0 {
+		l.pinned--
+		l.pinnedCost -= node.Val.cost
+		l.evictOverflow()
+	}
+
+	return true
+}
+
+// Pinned 返回被固定的元素数量
+func (l *LRU[K, V]) Pinned() int {
+	return l.pinned
+}
+
+// fits 判断写入开销为cost的元素后，能否只淘汰未固定的元素就回到容量以内
+//
+// node为被更新的元素，写入新元素时为nil。没有被固定的元素时总是可以写入，
+// 容量为0时新元素写入后立即被淘汰的行为保持不变。
+func (l *LRU[K, V]) fits(node *double_linked_list.DNode[entry[K, V]], cost int64) bool {
+	if l.pinned == 0 {
+		return true
+	}
+
+	if l.weigher == nil {
+		// 更新已存在的元素不改变元素数量
+		return node != nil || l.pinned+1 <= l.capacity
+	}
+
+	// 被更新的元素本身已被固定时，不重复计算
+	pinnedCost := l.pinnedCost
+	if node != nil && node.Val.pins > 0 {
+		pinnedCost -= node.Val.cost
+	}
+
+	return pinnedCost+cost <= l.maxCost
+}
@@ -0,0 +1,182 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// PinTestSuite 是元素固定的测试套件
+type PinTestSuite struct {
+	suite.Suite
+	lru *LRU[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *PinTestSuite) SetupTest() {
+	s.lru = NewLRU[string, int](3)
+}
+
+// TestEvictionSkipsPinned 测试淘汰时跳过被固定的元素
+func (s *PinTestSuite) TestEvictionSkipsPinned() {
+	s.NoError(s.lru.Put("a", 1))
+	s.NoError(s.lru.Put("b", 2))
+	s.NoError(s.lru.Put("c", 3))
+	s.True(s.lru.Pin("a"))
+	s.Equal(1, s.lru.Pinned())
+
+	// a最久未访问，但被固定，淘汰的是b
+	s.NoError(s.lru.Put("d", 4))
+	s.True(s.lru.Contains("a"))
+	s.False(s.lru.Contains("b"))
+	s.Equal([]string{"d", "c", "a"}, s.lru.Keys())
+}
+
+// TestAllPinned 测试所有元素都被固定时写入新元素返回错误且缓存不变
+func (s *PinTestSuite) TestAllPinned() {
+	for i, key := range []string{"a", "b", "c"} {
+		s.NoError(s.lru.Put(key, i))
+		s.True(s.lru.Pin(key))
+	}
+
+	s.ErrorIs(s.lru.Put("d", 4), ErrPinned)
+	s.False(s.lru.Contains("d"))
+	s.Equal(3, s.lru.Len())
+	s.Equal(uint64(3), s.lru.Stats().Insertions)
+
+	// 更新已存在的元素不需要腾出空间
+	s.NoError(s.lru.Put("a", 10))
+	v, _ := s.lru.Peek("a")
+	s.Equal(10, v)
+	s.Equal(3, s.lru.Pinned())
+
+	// 取消固定一个元素后可以写入
+	s.True(s.lru.Unpin("b"))
+	s.NoError(s.lru.Put("d", 4))
+	s.False(s.lru.Contains("b"))
+	s.True(s.lru.Contains("d"))
+}
+
+// TestRefCount 测试固定使用引用计数
+func (s *PinTestSuite) TestRefCount() {
+	s.False(s.lru.Pin("a"))
+	s.False(s.lru.Unpin("a"))
+
+	s.NoError(s.lru.Put("a", 1))
+	s.True(s.lru.Pin("a"))
+	s.True(s.lru.Pin("a"))
+	s.Equal(1, s.lru.Pinned())
+
+	s.True(s.lru.Unpin("a"))
+	s.Equal(1, s.lru.Pinned())
+	s.True(s.lru.Unpin("a"))
+	s.Equal(0, s.lru.Pinned())
+	s.False(s.lru.Unpin("a"))
+}
+
+// TestRemovePinned 测试被固定的元素仍然可以被删除，删除后不再计入固定数量
+func (s *PinTestSuite) TestRemovePinned() {
+	s.NoError(s.lru.Put("a", 1))
+	s.NoError(s.lru.Put("b", 2))
+	s.lru.Pin("a")
+	s.lru.Pin("b")
+
+	s.True(s.lru.Remove("a"))
+	s.Equal(1, s.lru.Pinned())
+	s.False(s.lru.Unpin("a"))
+
+	s.lru.Clear()
+	s.Equal(0, s.lru.Pinned())
+
+	// 重新写入的同名元素不再被固定
+	s.NoError(s.lru.Put("b", 2))
+	s.False(s.lru.Unpin("b"))
+}
+
+// TestPinnedExpires 测试被固定的元素仍然会过期
+func (s *PinTestSuite) TestPinnedExpires() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	l := NewLRU[string, int](2, cache.WithClock(clk))
+	s.NoError(l.PutWithTTL("a", 1, time.Second))
+	s.True(l.Pin("a"))
+
+	clk.Advance(time.Second)
+	s.False(l.Pin("a"))
+	s.Equal(1, l.PurgeExpired())
+	s.Equal(0, l.Pinned())
+}
+
+// TestResizeBelowPinned 测试容量缩小到被固定元素数量以下时，取消固定后再淘汰
+func (s *PinTestSuite) TestResizeBelowPinned() {
+	var evicted []string
+	s.lru.OnEvict(func(key string, _ int, reason cache.EvictReason) {
+		if reason == cache.EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	})
+
+	for i, key := range []string{"a", "b", "c"} {
+		s.NoError(s.lru.Put(key, i))
+	}
+	s.lru.Pin("a")
+	s.lru.Pin("b")
+
+	s.Equal(1, s.lru.Resize(1))
+	s.Equal([]string{"c"}, evicted)
+	s.Equal(2, s.lru.Len())
+
+	s.lru.Unpin("a")
+	s.Equal([]string{"c", "a"}, evicted)
+	s.Equal([]string{"b"}, s.lru.Keys())
+}
+
+// TestUpdateAfterResize 测试容量缩小到被固定元素数量以下后，更新已存在的元素不需要腾出空间
+func (s *PinTestSuite) TestUpdateAfterResize() {
+	for i, key := range []string{"a", "b", "c"} {
+		s.NoError(s.lru.Put(key, i))
+		s.True(s.lru.Pin(key))
+	}
+
+	s.Equal(0, s.lru.Resize(2))
+	s.NoError(s.lru.Put("a", 10))
+	v, _ := s.lru.Peek("a")
+	s.Equal(10, v)
+	s.ErrorIs(s.lru.Put("d", 4), ErrPinned)
+
+	// 取消固定后超出容量的元素被淘汰，剩下的未固定元素仍然可以更新
+	s.True(s.lru.Unpin("a"))
+	s.True(s.lru.Unpin("b"))
+	s.Equal([]string{"c", "b"}, s.lru.Keys())
+	s.NoError(s.lru.Put("b", 20))
+	s.Equal([]string{"b", "c"}, s.lru.Keys())
+}
+
+// TestWeighted 测试按开销限制容量时被固定元素的开销
+func (s *PinTestSuite) TestWeighted() {
+	l := NewWeightedLRU[string, int](10, func(_ string, v int) int64 { return int64(v) })
+	s.NoError(l.Put("a", 4))
+	s.NoError(l.Put("b", 4))
+	s.True(l.Pin("a"))
+
+	// 淘汰b后可以容纳
+	s.NoError(l.Put("c", 6))
+	s.Equal([]string{"c", "a"}, l.Keys())
+
+	s.True(l.Pin("c"))
+	s.ErrorIs(l.Put("d", 1), ErrPinned)
+
+	// 被固定元素的更新按新开销计算
+	s.ErrorIs(l.Put("c", 7), ErrPinned)
+	s.NoError(l.Put("c", 5))
+	s.Equal(int64(9), l.Cost())
+	s.NoError(l.Put("d", 1))
+	s.Equal(int64(10), l.Cost())
+}
+
+// TestPin 运行所有元素固定测试
+func TestPin(t *testing.T) {
+	suite.Run(t, new(PinTestSuite))
+}
@@ -0,0 +1,271 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"hash/maphash"
+	"slices"
+	"sync"
+	"time"
+)
+
+// shard 是 ShardedLRU 中一个独立加锁的分片
+type shard[K comparable, V any] struct {
+	sync.Mutex
+	lru     *LRU[K, V]
+	pending cache.PendingEvictions[K, V] // 持锁期间离开缓存、尚未回调的元素
+}
+
+// ShardedLRU 并发安全的分片LRU缓存
+//
+// 键按哈希值分散到多个分片上，每个分片是一个由独立互斥锁保护的LRU，
+// 不同分片上的操作互不阻塞。淘汰只在分片内部进行，因此整体上是近似LRU；
+// 只有一个分片时（见 NewSyncLRU）严格遵循LRU顺序。
+type ShardedLRU[K comparable, V any] struct {
+	seed    maphash.Seed
+	shards  []*shard[K, V]
+	clock   clock.Clock
+	onEvict func(key K, value V, reason cache.EvictReason)
+	flight  *flight[K, V]
+}
+
+var _ cache.Cache[string, int] = (*ShardedLRU[string, int])(nil)
+
+// NewShardedLRU 创建一个总容量为capacity、包含shardCount个分片的并发安全LRU
+//
+// 容量尽量均分到各个分片上，分片数不会超过容量。opts中的容量会被忽略，
+// 通过 cache.WithOnEvict 指定的回调与 OnEvict 相同，在分片锁之外执行。
+func NewShardedLRU[K comparable, V any](capacity, shardCount int, opts ...cache.Option) *ShardedLRU[K, V] {
+	if capacity > 0 && shardCount > capacity {
+		shardCount = capacity
+	}
+	if shardCount < 1 {
+		shardCount = 1
+	}
+
+	o := cache.NewOptions(opts...)
+	s := &ShardedLRU[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*shard[K, V], shardCount),
+		clock:  o.Clock,
+	}
+	s.flight = newFlight[K, V](s, o.Clock, negativeTTL(o))
+
+	for i := range s.shards {
+		shardCap := shardCapacity(capacity, shardCount, i)
+		s.shards[i] = &shard[K, V]{lru: New[K, V](slices.Concat(opts, []cache.Option{cache.WithCapacity(shardCap)})...)}
+	}
+	s.OnEvict(cache.OnEvict[K, V](o))
+
+	return s
+}
+
+// shardCapacity 返回把capacity均分到count个分片时第i个分片的容量
+func shardCapacity(capacity, count, i int) int {
+	shardCap := capacity / count
+	if i < capacity%count {
+		shardCap++
+	}
+
+	return shardCap
+}
+
+// NewSyncLRU 创建一个只有一个分片的并发安全LRU，即用一把互斥锁保护的LRU
+func NewSyncLRU[K comparable, V any](capacity int, opts ...cache.Option) *ShardedLRU[K, V] {
+	return NewShardedLRU[K, V](capacity, 1, opts...)
+}
+
+func (s *ShardedLRU[K, V]) shardFor(key K) *shard[K, V] {
+	if len(s.shards) == 1 {
+		return s.shards[0]
+	}
+
+	h := maphash.Comparable(s.seed, key)
+	return s.shards[h%uint64(len(s.shards))]
+}
+
+// unlock 释放分片锁，并在锁外执行持锁期间积攒的淘汰回调
+func (s *ShardedLRU[K, V]) unlock(sh *shard[K, V]) {
+	sh.pending.Unlock(sh, s.onEvict)
+}
+
+func (s *ShardedLRU[K, V]) Get(key K) (V, bool) {
+	sh := s.shardFor(key)
+	sh.Lock()
+	val, ok := sh.lru.Get(key)
+	s.unlock(sh)
+
+	return val, ok
+}
+
+func (s *ShardedLRU[K, V]) Peek(key K) (V, bool) {
+	sh := s.shardFor(key)
+	sh.Lock()
+	val, ok := sh.lru.Peek(key)
+	s.unlock(sh)
+
+	return val, ok
+}
+
+func (s *ShardedLRU[K, V]) Contains(key K) bool {
+	_, ok := s.Peek(key)
+	return ok
+}
+
+func (s *ShardedLRU[K, V]) Put(key K, value V) error {
+	sh := s.shardFor(key)
+	sh.Lock()
+	err := sh.lru.Put(key, value)
+	s.unlock(sh)
+
+	return err
+}
+
+func (s *ShardedLRU[K, V]) PutWithTTL(key K, value V, ttl time.Duration) error {
+	sh := s.shardFor(key)
+	sh.Lock()
+	err := sh.lru.PutWithTTL(key, value, ttl)
+	s.unlock(sh)
+
+	return err
+}
+
+func (s *ShardedLRU[K, V]) Remove(key K) bool {
+	sh := s.shardFor(key)
+	sh.Lock()
+	ok := sh.lru.Remove(key)
+	s.unlock(sh)
+
+	return ok
+}
+
+// PurgeExpired 逐个分片删除已过期的元素，返回删除的数量
+func (s *ShardedLRU[K, V]) PurgeExpired() int {
+	purged := 0
+	for _, sh := range s.shards {
+		sh.Lock()
+		purged += sh.lru.PurgeExpired()
+		s.unlock(sh)
+	}
+
+	return purged
+}
+
+// OnEvict 设置元素离开缓存时的回调，传入nil取消回调
+//
+// 回调在分片锁之外执行，因此可以在回调中再次访问缓存。
+// 应在并发使用缓存之前设置。
+func (s *ShardedLRU[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	s.onEvict = fn
+	for _, sh := range s.shards {
+		sh.Lock()
+		if fn == nil {
+			sh.lru.OnEvict(nil)
+		} else {
+			sh.lru.OnEvict(func(key K, value V, reason cache.EvictReason) {
+				sh.pending.Add(key, value, reason)
+			})
+		}
+		sh.Unlock()
+	}
+}
+
+// StartJanitor 启动一个后台协程，每隔interval调用一次 PurgeExpired
+//
+// 返回的stop函数用于停止该协程，可以重复调用。
+func (s *ShardedLRU[K, V]) StartJanitor(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-s.clock.After(interval):
+				s.PurgeExpired()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// Size 返回所有分片中元素数量之和
+func (s *ShardedLRU[K, V]) Size() int {
+	size := 0
+	for _, sh := range s.shards {
+		sh.Lock()
+		size += sh.lru.Size()
+		sh.Unlock()
+	}
+
+	return size
+}
+
+// Len 与 Size 相同
+func (s *ShardedLRU[K, V]) Len() int {
+	return s.Size()
+}
+
+// Resize 修改总容量并重新分配到各分片，容量缩小时各分片立即淘汰最久未访问的元素，
+// 返回淘汰的数量
+//
+// 分片数保持不变，容量小于分片数时部分分片的容量为0。淘汰回调在分片锁之外执行。
+func (s *ShardedLRU[K, V]) Resize(capacity int) int {
+	evicted := 0
+	for i, sh := range s.shards {
+		sh.Lock()
+		evicted += sh.lru.Resize(shardCapacity(max(capacity, 0), len(s.shards), i))
+		s.unlock(sh)
+	}
+
+	return evicted
+}
+
+// Stats 返回所有分片统计信息之和，读取时不需要加锁
+func (s *ShardedLRU[K, V]) Stats() cache.Stats {
+	total := cache.Stats{Evictions: make(map[cache.EvictReason]uint64)}
+	for _, sh := range s.shards {
+		st := sh.lru.Stats()
+		total.Hits += st.Hits
+		total.Misses += st.Misses
+		total.Insertions += st.Insertions
+		total.Updates += st.Updates
+		total.Size += st.Size
+		for reason, n := range st.Evictions {
+			total.Evictions[reason] += n
+		}
+	}
+
+	return total
+}
+
+// ResetStats 将所有分片的统计计数清零
+func (s *ShardedLRU[K, V]) ResetStats() {
+	for _, sh := range s.shards {
+		sh.lru.ResetStats()
+	}
+}
+
+// Keys 返回所有未过期的键，同一分片内按从最近到最久访问的顺序排列
+func (s *ShardedLRU[K, V]) Keys() []K {
+	var keys []K
+	for _, sh := range s.shards {
+		sh.Lock()
+		keys = append(keys, sh.lru.Keys()...)
+		sh.Unlock()
+	}
+
+	return keys
+}
+
+// Clear 逐个分片删除所有元素
+func (s *ShardedLRU[K, V]) Clear() {
+	for _, sh := range s.shards {
+		sh.Lock()
+		sh.lru.Clear()
+		s.unlock(sh)
+	}
+}
@@ -0,0 +1,297 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/clock"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// ShardedLRUTestSuite 是分片LRU缓存的测试套件
+type ShardedLRUTestSuite struct {
+	suite.Suite
+	lru *ShardedLRU[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *ShardedLRUTestSuite) SetupTest() {
+	s.lru = NewShardedLRU[string, int](64, 4)
+}
+
+// TestNewShardedLRU 测试容量在分片间的分配
+func (s *ShardedLRUTestSuite) TestNewShardedLRU() {
+	s.Len(s.lru.shards, 4)
+	for _, sh := range s.lru.shards {
+		s.Equal(16, sh.lru.capacity)
+	}
+
+	// 容量不能整除时余数分给前面的分片
+	l := NewShardedLRU[string, int](10, 4)
+	total := 0
+	for _, sh := range l.shards {
+		total += sh.lru.capacity
+	}
+	s.Equal(10, total)
+
+	// 分片数不超过容量
+	l = NewShardedLRU[string, int](2, 8)
+	s.Len(l.shards, 2)
+
+	// 非法分片数退化为单分片
+	l = NewShardedLRU[string, int](2, 0)
+	s.Len(l.shards, 1)
+}
+
+// TestPutGetRemove 测试基本的Put、Get和Remove操作
+//
+// 使用单分片，避免键在分片间分布不均时被淘汰
+func (s *ShardedLRUTestSuite) TestPutGetRemove() {
+	l := NewSyncLRU[string, int](32)
+	for i := range 32 {
+		l.Put(strconv.Itoa(i), i)
+	}
+	s.Equal(32, l.Size())
+
+	for i := range 32 {
+		val, ok := l.Get(strconv.Itoa(i))
+		s.True(ok)
+		s.Equal(i, val)
+	}
+
+	s.True(l.Remove("0"))
+	s.False(l.Remove("0"))
+	_, ok := l.Get("0")
+	s.False(ok)
+	s.Equal(31, l.Size())
+}
+
+// TestCapacityBound 测试总大小不超过容量
+func (s *ShardedLRUTestSuite) TestCapacityBound() {
+	for i := range 1000 {
+		s.lru.Put(strconv.Itoa(i), i)
+	}
+	s.LessOrEqual(s.lru.Size(), 64)
+}
+
+// TestSyncLRUEviction 测试单分片时严格遵循LRU淘汰顺序
+func (s *ShardedLRUTestSuite) TestSyncLRUEviction() {
+	l := NewSyncLRU[string, int](2)
+	l.Put("key1", 1)
+	l.Put("key2", 2)
+	l.Get("key1")
+	l.Put("key3", 3)
+
+	_, ok := l.Get("key2")
+	s.False(ok)
+	_, ok = l.Get("key1")
+	s.True(ok)
+	_, ok = l.Get("key3")
+	s.True(ok)
+}
+
+// TestJanitor 测试后台协程定期清理过期元素
+//
+// 使用单分片，避免键在分片间分布不均时被淘汰
+func (s *ShardedLRUTestSuite) TestJanitor() {
+	synctest.Test(s.T(), func(t *testing.T) {
+		clk := clock.NewManual(time.Unix(0, 0))
+		l := NewSyncLRU[string, int](8, cache.WithClock(clk))
+		for i := range 4 {
+			l.PutWithTTL(strconv.Itoa(i), i, time.Second)
+		}
+		l.Put("forever", 0)
+
+		stop := l.StartJanitor(time.Minute)
+		defer stop()
+		synctest.Wait()
+
+		clk.Advance(time.Second)
+		synctest.Wait()
+		s.Equal(5, l.Size())
+
+		clk.Advance(time.Minute)
+		synctest.Wait()
+		s.Equal(1, l.Size())
+
+		stop()
+		stop()
+		synctest.Wait()
+	})
+}
+
+// TestOnEvictOutsideLock 测试回调在锁外执行，回调中可以再次访问缓存
+func (s *ShardedLRUTestSuite) TestOnEvictOutsideLock() {
+	l := NewSyncLRU[string, int](1)
+	var reasons []cache.EvictReason
+	l.OnEvict(func(key string, value int, reason cache.EvictReason) {
+		reasons = append(reasons, reason)
+		// 在锁内执行会导致死锁
+		l.Get(key)
+	})
+
+	l.Put("key1", 1)
+	l.Put("key2", 2)
+	l.Put("key2", 20)
+	l.Remove("key2")
+
+	s.Equal([]cache.EvictReason{cache.EvictCapacity, cache.EvictReplaced, cache.EvictRemoved}, reasons)
+}
+
+// TestPeekKeysClear 测试Peek、Keys和Clear
+func (s *ShardedLRUTestSuite) TestPeekKeysClear() {
+	var removed int
+	l := NewShardedLRU[string, int](8, 2, cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		if reason == cache.EvictRemoved {
+			removed++
+		}
+	}))
+	for i := range 4 {
+		s.NoError(l.Put(strconv.Itoa(i), i))
+	}
+
+	val, ok := l.Peek("1")
+	s.True(ok)
+	s.Equal(1, val)
+	s.True(l.Contains("1"))
+	s.False(l.Contains("9"))
+	s.ElementsMatch([]string{"0", "1", "2", "3"}, l.Keys())
+	s.Equal(4, l.Len())
+
+	l.Clear()
+	s.Equal(0, l.Len())
+	s.Empty(l.Keys())
+	s.Equal(4, removed)
+}
+
+// TestConcurrentAccess 测试并发读写，需配合 -race 运行
+func (s *ShardedLRUTestSuite) TestConcurrentAccess() {
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				key := strconv.Itoa((g*1000 + i) % 100)
+				s.lru.Put(key, i)
+				s.lru.Get(key)
+				if i%10 == 0 {
+					s.lru.Remove(key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	s.LessOrEqual(s.lru.Size(), 64)
+}
+
+// TestStats 测试统计信息汇总所有分片，并且可以在并发读写时读取
+func (s *ShardedLRUTestSuite) TestStats() {
+	var wg sync.WaitGroup
+	for g := range 4 {
+		wg.Go(func() {
+			for i := range 500 {
+				key := strconv.Itoa(g*500 + i)
+				s.lru.Put(key, i)
+				s.lru.Get(key)
+				s.lru.Stats()
+			}
+		})
+	}
+	wg.Wait()
+
+	st := s.lru.Stats()
+	s.Equal(uint64(2000), st.Insertions)
+	s.Equal(st.Hits+st.Misses, uint64(2000))
+	s.Equal(uint64(2000-64), st.Evictions[cache.EvictCapacity])
+	s.Equal(64, st.Size)
+
+	s.lru.ResetStats()
+	st = s.lru.Stats()
+	s.Zero(st.Insertions)
+	s.Zero(st.Evicted())
+	s.Equal(64, st.Size)
+}
+
+// TestResize 测试缩小总容量时各分片淘汰元素并在锁外触发回调
+func (s *ShardedLRUTestSuite) TestResize() {
+	var evicted int
+	l := NewShardedLRU[string, int](64, 4, cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		s.Equal(cache.EvictCapacity, reason)
+		evicted++
+	}))
+	for i := range 1000 {
+		s.NoError(l.Put(strconv.Itoa(i), i))
+	}
+	s.Equal(64, l.Len())
+	evicted = 0
+
+	s.Equal(54, l.Resize(10))
+	s.Equal(54, evicted)
+	s.Equal(10, l.Len())
+	total := 0
+	for _, sh := range l.shards {
+		total += sh.lru.capacity
+	}
+	s.Equal(10, total)
+
+	// 扩大容量不淘汰元素
+	s.Equal(0, l.Resize(100))
+	for i := range 1000 {
+		s.NoError(l.Put("new"+strconv.Itoa(i), i))
+	}
+	s.LessOrEqual(l.Len(), 100)
+	s.Greater(l.Len(), 64)
+
+	// 容量小于分片数时部分分片的容量为0
+	s.Greater(l.Resize(2), 0)
+	s.LessOrEqual(l.Len(), 2)
+}
+
+// TestShardedLRU 运行所有分片LRU测试
+func TestShardedLRU(t *testing.T) {
+	suite.Run(t, new(ShardedLRUTestSuite))
+}
+
+// TestSyncConformance 对单分片的并发安全LRU运行缓存接口的一致性测试
+func TestSyncConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return NewSyncLRU[string, int](cache.NewOptions(opts...).Capacity, opts...)
+	})
+}
+
+func benchmarkParallel(b *testing.B, l *ShardedLRU[string, int]) {
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+		l.Put(keys[i], i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%4 == 0 {
+				l.Put(key, i)
+			} else {
+				l.Get(key)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkSyncLRU 单锁LRU的并发吞吐
+func BenchmarkSyncLRU(b *testing.B) {
+	benchmarkParallel(b, NewSyncLRU[string, int](2048))
+}
+
+// BenchmarkShardedLRU 分片LRU的并发吞吐
+func BenchmarkShardedLRU(b *testing.B) {
+	benchmarkParallel(b, NewShardedLRU[string, int](2048, 32))
+}
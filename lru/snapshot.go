@@ -0,0 +1,63 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"errors"
+	"io"
+	"time"
+)
+
+// snapshotKind 标识LRU的快照
+const snapshotKind = "lru"
+
+// record 是快照中的一个元素
+type record[K comparable, V any] struct {
+	Key      K
+	Value    V
+	ExpireAt time.Time
+	Tags     []string
+}
+
+// Snapshot 把所有未过期的元素按从最久到最近访问的顺序写入w
+//
+// 编解码方式通过 cache.WithCodec 指定，默认使用gob。
+func (l *LRU[K, V]) Snapshot(w io.Writer) error {
+	now := l.clock.Now()
+	records := make([]record[K, V], 0, l.list.Size())
+	for node := range l.list.Nodes() {
+		if !l.expired(node.Val, now) {
+			records = append(records, record[K, V]{node.Val.key, node.Val.value, node.Val.expireAt, node.Val.tags})
+		}
+	}
+
+	return cache.WriteSnapshot(w, l.codec, snapshotKind, records)
+}
+
+// Restore 用 Snapshot 写入的快照替换缓存的内容，恢复后的访问顺序与快照时相同
+//
+// 元素的标签随快照一起恢复。快照校验失败时返回错误，缓存保持不变。恢复前已有的元素以 cache.EvictRemoved
+// 触发回调；快照中已过期的元素被跳过，超出容量的元素按写入顺序被淘汰。
+func (l *LRU[K, V]) Restore(r io.Reader) error {
+	var records []record[K, V]
+	if err := cache.ReadSnapshot(r, l.codec, snapshotKind, &records); err != nil {
+		return err
+	}
+
+	l.Clear()
+	now := l.clock.Now()
+	for _, rec := range records {
+		var ttl time.Duration
+		if !rec.ExpireAt.IsZero() {
+			if ttl = rec.ExpireAt.Sub(now); ttl <= 0 {
+				continue
+			}
+		}
+
+		// 按开销限制容量时跳过超过上限的元素
+		if err := l.put(rec.Key, rec.Value, ttl, rec.Tags, true); err != nil && !errors.Is(err, ErrTooLarge) {
+			return err
+		}
+	}
+
+	return nil
+}
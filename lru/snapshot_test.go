@@ -0,0 +1,136 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"algorithm/clock"
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SnapshotTestSuite 是LRU快照的测试套件
+type SnapshotTestSuite struct {
+	suite.Suite
+}
+
+// evictionOrder 写入n个新键，返回元素因容量不足被淘汰的顺序
+func evictionOrder(l *LRU[string, int], n int) []string {
+	var order []string
+	l.OnEvict(func(key string, value int, reason cache.EvictReason) {
+		if reason == cache.EvictCapacity {
+			order = append(order, key)
+		}
+	})
+	for i := range n {
+		l.Put("fresh"+strconv.Itoa(i), i)
+	}
+
+	return order
+}
+
+// TestSameEvictionOrder 测试恢复后的缓存与原缓存的淘汰顺序完全相同
+func (s *SnapshotTestSuite) TestSameEvictionOrder() {
+	for _, codec := range []cache.Codec{cache.GobCodec, cache.JSONCodec} {
+		orig := NewLRU[string, int](8, cache.WithCodec(codec))
+		for i := range 8 {
+			orig.Put("key"+strconv.Itoa(i), i)
+		}
+		orig.Get("key3")
+		orig.Get("key0")
+		orig.Put("key5", 50)
+
+		var buf bytes.Buffer
+		s.NoError(orig.Snapshot(&buf))
+
+		restored := NewLRU[string, int](8, cache.WithCodec(codec))
+		s.NoError(restored.Restore(&buf))
+		s.Equal(orig.Keys(), restored.Keys())
+		val, _ := restored.Peek("key5")
+		s.Equal(50, val)
+
+		s.Equal(evictionOrder(orig, 8), evictionOrder(restored, 8))
+	}
+}
+
+// TestRestoreTTL 测试恢复时保留过期时间并跳过已过期的元素
+func (s *SnapshotTestSuite) TestRestoreTTL() {
+	clk := clock.NewManual(time.Unix(0, 0))
+	orig := NewLRU[string, int](4, cache.WithClock(clk))
+	orig.Put("forever", 1)
+	orig.PutWithTTL("short", 2, time.Second)
+	orig.PutWithTTL("long", 3, time.Minute)
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+	clk.Advance(2 * time.Second)
+
+	restored := NewLRU[string, int](4, cache.WithClock(clk))
+	s.NoError(restored.Restore(&buf))
+	s.Equal([]string{"long", "forever"}, restored.Keys())
+
+	clk.Advance(time.Minute)
+	s.False(restored.Contains("long"))
+	s.True(restored.Contains("forever"))
+}
+
+// TestRestoreSmaller 测试恢复到容量更小的缓存时淘汰最久未访问的元素
+func (s *SnapshotTestSuite) TestRestoreSmaller() {
+	orig := NewLRU[string, int](4)
+	for i := range 4 {
+		orig.Put("key"+strconv.Itoa(i), i)
+	}
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+
+	restored := NewLRU[string, int](2)
+	s.NoError(restored.Restore(&buf))
+	s.Equal([]string{"key3", "key2"}, restored.Keys())
+}
+
+// TestRestoreReplaces 测试恢复前已有的元素被删除
+func (s *SnapshotTestSuite) TestRestoreReplaces() {
+	orig := NewLRU[string, int](4)
+	orig.Put("key1", 1)
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+
+	var removed []string
+	restored := NewLRU[string, int](4, cache.WithOnEvict(func(key string, value int, reason cache.EvictReason) {
+		if reason == cache.EvictRemoved {
+			removed = append(removed, key)
+		}
+	}))
+	restored.Put("old", 0)
+	s.NoError(restored.Restore(&buf))
+	s.Equal([]string{"old"}, removed)
+	s.Equal([]string{"key1"}, restored.Keys())
+}
+
+// TestRestoreCorrupted 测试快照损坏时返回错误且缓存保持不变
+func (s *SnapshotTestSuite) TestRestoreCorrupted() {
+	orig := NewLRU[string, int](4)
+	orig.Put("key1", 1)
+
+	var buf bytes.Buffer
+	s.NoError(orig.Snapshot(&buf))
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xff
+
+	restored := NewLRU[string, int](4)
+	restored.Put("old", 0)
+	s.ErrorIs(restored.Restore(bytes.NewReader(data)), cache.ErrBadSnapshot)
+	s.Equal([]string{"old"}, restored.Keys())
+
+	// 空的快照
+	s.ErrorIs(restored.Restore(bytes.NewReader(nil)), cache.ErrBadSnapshot)
+}
+
+// TestSnapshot 运行所有LRU快照测试
+func TestSnapshot(t *testing.T) {
+	suite.Run(t, new(SnapshotTestSuite))
+}
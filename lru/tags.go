@@ -0,0 +1,106 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"slices"
+	"strings"
+)
+
+// PutWithTags 写入元素并把它的标签替换为tags，过期时间为默认TTL
+//
+// 标签用于 InvalidateTag 批量删除元素；通过 Put 或 PutWithTTL 更新元素时
+// 原有的标签保持不变。错误与 PutWithTTL 相同。
+func (l *LRU[K, V]) PutWithTags(key K, value V, tags ...string) error {
+	return l.put(key, value, l.defaultTTL, tags, true)
+}
+
+// Tags 返回key对应元素的标签，元素不存在或已过期时返回nil
+func (l *LRU[K, V]) Tags(key K) []string {
+	node, ok := l.cache[key]
+	if !ok || l.expired(node.Val, l.clock.Now()) {
+		return nil
+	}
+
+	return slices.Clone(node.Val.tags)
+}
+
+// InvalidateTag 删除所有带有标签tag的元素，返回删除的数量
+//
+// 每个被删除的元素都以 cache.EvictRemoved 触发回调，被固定的元素也会被删除。
+func (l *LRU[K, V]) InvalidateTag(tag string) int {
+	keys := make([]K, 0, len(l.tags[tag]))
+	for key := range l.tags[tag] {
+		keys = append(keys, key)
+	}
+
+	return l.removeKeys(keys)
+}
+
+// InvalidatePrefix 删除所有键以prefix开头的元素，返回删除的数量
+//
+// 只适用于底层类型为string的键，其他类型的键返回0。通过 WithPrefixIndex
+// 启用前缀索引时只访问匹配的键，否则需要遍历所有元素。回调与 InvalidateTag 相同。
+func (l *LRU[K, V]) InvalidatePrefix(prefix string) int {
+	if l.keyString == nil {
+		return 0
+	}
+
+	var keys []K
+	if l.prefixes != nil {
+		keys = l.prefixes.collect(prefix)
+	} else {
+		for node := range l.list.Nodes() {
+			if strings.HasPrefix(l.keyString(node.Val.key), prefix) {
+				keys = append(keys, node.Val.key)
+			}
+		}
+	}
+
+	return l.removeKeys(keys)
+}
+
+func (l *LRU[K, V]) removeKeys(keys []K) int {
+	removed := 0
+	for _, key := range keys {
+		if node, ok := l.cache[key]; ok && l.removeNode(node, cache.EvictRemoved) {
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// index 把新元素加入标签索引和前缀索引
+func (l *LRU[K, V]) index(e entry[K, V]) {
+	l.tags.add(e.key, e.tags)
+	if l.prefixes != nil {
+		l.prefixes.insert(l.keyString(e.key), e.key)
+	}
+}
+
+// unindex 把元素从标签索引和前缀索引中删除
+func (l *LRU[K, V]) unindex(e entry[K, V]) {
+	l.tags.remove(e.key, e.tags)
+	if l.prefixes != nil {
+		l.prefixes.remove(l.keyString(e.key))
+	}
+}
+
+// resetIndex 清空所有索引
+func (l *LRU[K, V]) resetIndex() {
+	l.tags = make(tagIndex[K])
+	if l.prefixes != nil {
+		l.prefixes = &trie[K]{}
+	}
+}
+
+// normalizeTags 返回去重并排序后的标签，没有标签时返回nil
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
@@ -0,0 +1,152 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TagsTestSuite 是按标签和前缀批量删除的测试套件
+type TagsTestSuite struct {
+	suite.Suite
+	lru     *LRU[string, int]
+	removed []string
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *TagsTestSuite) SetupTest() {
+	s.removed = nil
+	s.lru = NewLRU[string, int](10, cache.WithOnEvict(func(key string, _ int, reason cache.EvictReason) {
+		if reason == cache.EvictRemoved {
+			s.removed = append(s.removed, key)
+		}
+	}))
+}
+
+// TestInvalidateTag 测试删除带有指定标签的所有元素
+func (s *TagsTestSuite) TestInvalidateTag() {
+	s.NoError(s.lru.PutWithTags("a", 1, "tenant:1", "user"))
+	s.NoError(s.lru.PutWithTags("b", 2, "tenant:1"))
+	s.NoError(s.lru.PutWithTags("c", 3, "tenant:2", "user"))
+	s.NoError(s.lru.Put("d", 4))
+
+	s.Equal(2, s.lru.InvalidateTag("tenant:1"))
+	s.ElementsMatch([]string{"a", "b"}, s.removed)
+	s.Equal([]string{"d", "c"}, s.lru.Keys())
+
+	// a已被删除，user标签只剩c
+	s.Equal(1, s.lru.InvalidateTag("user"))
+	s.Equal(0, s.lru.InvalidateTag("user"))
+	s.Equal(0, s.lru.InvalidateTag("unknown"))
+	s.Empty(s.lru.tags)
+}
+
+// TestRetag 测试 PutWithTags 替换标签，Put 保留标签
+func (s *TagsTestSuite) TestRetag() {
+	s.NoError(s.lru.PutWithTags("a", 1, "x", "y", "x"))
+	s.Equal([]string{"x", "y"}, s.lru.Tags("a"))
+
+	s.NoError(s.lru.Put("a", 2))
+	s.Equal([]string{"x", "y"}, s.lru.Tags("a"))
+
+	s.NoError(s.lru.PutWithTags("a", 3, "z"))
+	s.Equal([]string{"z"}, s.lru.Tags("a"))
+	s.Equal(0, s.lru.InvalidateTag("x"))
+	s.Equal(1, s.lru.InvalidateTag("z"))
+
+	s.Nil(s.lru.Tags("a"))
+}
+
+// TestEvictionKeepsIndexConsistent 测试淘汰、删除和清空后索引保持一致
+func (s *TagsTestSuite) TestEvictionKeepsIndexConsistent() {
+	l := NewLRU[string, int](2, WithPrefixIndex())
+	s.NoError(l.PutWithTags("t1:a", 1, "t1"))
+	s.NoError(l.PutWithTags("t1:b", 2, "t1"))
+	s.NoError(l.PutWithTags("t2:a", 3, "t2"))
+
+	// t1:a 被淘汰后不再出现在索引中
+	s.Equal([]string{"t1:b"}, l.prefixes.collect("t1:"))
+	s.Len(l.tags["t1"], 1)
+
+	s.True(l.Remove("t1:b"))
+	s.NotContains(l.tags, "t1")
+	s.Empty(l.prefixes.collect("t1"))
+
+	l.Clear()
+	s.Empty(l.tags)
+	s.Empty(l.prefixes.collect(""))
+
+	// 重新写入同名元素时使用新的标签
+	s.NoError(l.Put("t2:a", 4))
+	s.Equal(0, l.InvalidateTag("t2"))
+	s.Equal(1, l.InvalidatePrefix("t2"))
+}
+
+// TestInvalidatePrefix 测试按前缀删除元素，有无前缀索引的结果相同
+func (s *TagsTestSuite) TestInvalidatePrefix() {
+	for _, opts := range [][]cache.Option{nil, {WithPrefixIndex()}} {
+		l := NewLRU[string, int](10, opts...)
+		for _, key := range []string{"user:1", "user:10", "user:2", "users", "order:1", ""} {
+			s.NoError(l.Put(key, 0))
+		}
+
+		s.Equal(3, l.InvalidatePrefix("user:"))
+		s.ElementsMatch([]string{"users", "order:1", ""}, l.Keys())
+		s.Equal(0, l.InvalidatePrefix("user:"))
+		s.Equal(0, l.InvalidatePrefix("product"))
+
+		// 空前缀匹配所有键
+		s.Equal(3, l.InvalidatePrefix(""))
+		s.Equal(0, l.Len())
+	}
+}
+
+// TestPrefixNamedString 测试底层类型为string的键也支持前缀删除，其他类型返回0
+func (s *TagsTestSuite) TestPrefixNamedString() {
+	type tenantKey string
+	l := NewLRU[tenantKey, int](10, WithPrefixIndex())
+	s.NoError(l.Put("acme/a", 1))
+	s.NoError(l.Put("acme/b", 2))
+	s.NoError(l.Put("other/a", 3))
+	s.Equal(2, l.InvalidatePrefix("acme/"))
+	s.Equal([]tenantKey{"other/a"}, l.Keys())
+
+	ints := NewLRU[int, int](10, WithPrefixIndex())
+	s.NoError(ints.Put(12, 1))
+	s.Nil(ints.prefixes)
+	s.Equal(0, ints.InvalidatePrefix("1"))
+	s.Equal(1, ints.Len())
+}
+
+// TestPinnedInvalidated 测试被固定的元素也会被批量删除
+func (s *TagsTestSuite) TestPinnedInvalidated() {
+	s.NoError(s.lru.PutWithTags("a", 1, "t"))
+	s.True(s.lru.Pin("a"))
+
+	s.Equal(1, s.lru.InvalidateTag("t"))
+	s.Equal(0, s.lru.Pinned())
+}
+
+// TestSnapshotTags 测试标签随快照恢复
+func (s *TagsTestSuite) TestSnapshotTags() {
+	for i := range 3 {
+		s.NoError(s.lru.PutWithTags(strconv.Itoa(i), i, "t"+strconv.Itoa(i%2)))
+	}
+
+	var buf bytes.Buffer
+	s.NoError(s.lru.Snapshot(&buf))
+
+	restored := NewLRU[string, int](10)
+	s.NoError(restored.Restore(&buf))
+	s.Equal([]string{"t0"}, restored.Tags("2"))
+	s.Equal(2, restored.InvalidateTag("t0"))
+	s.Equal([]string{"1"}, restored.Keys())
+}
+
+// TestTags 运行所有标签和前缀测试
+func TestTags(t *testing.T) {
+	suite.Run(t, new(TagsTestSuite))
+}
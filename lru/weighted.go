@@ -0,0 +1,45 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"errors"
+)
+
+// ErrTooLarge 表示单个元素的开销超过了缓存的开销上限
+var ErrTooLarge = errors.New("lru: item cost exceeds max cost")
+
+// NewWeightedLRU 创建按开销限制容量的LRU缓存
+//
+// 每个元素的开销由weigher计算，写入后从最久未访问的一端淘汰元素，
+// 直到总开销不超过maxCost。weigher返回的负数按0计算；opts中的容量会被忽略。
+func NewWeightedLRU[K comparable, V any](maxCost int64, weigher func(key K, value V) int64, opts ...cache.Option) *LRU[K, V] {
+	l := New[K, V](opts...)
+	l.weigher = weigher
+	l.maxCost = maxCost
+
+	return l
+}
+
+// Cost 返回当前所有元素的总开销，未按开销限制容量时为0
+func (l *LRU[K, V]) Cost() int64 {
+	return l.cost
+}
+
+// MaxCost 返回开销上限，未按开销限制容量时为0
+func (l *LRU[K, V]) MaxCost() int64 {
+	return l.maxCost
+}
+
+// weigh 计算元素的开销，超过上限时返回 ErrTooLarge
+func (l *LRU[K, V]) weigh(key K, value V) (int64, error) {
+	if l.weigher == nil {
+		return 0, nil
+	}
+
+	cost := max(l.weigher(key, value), 0)
+	if cost > l.maxCost {
+		return 0, ErrTooLarge
+	}
+
+	return cost, nil
+}
@@ -0,0 +1,132 @@
+package lru
+
+import (
+	"algorithm/cache"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// WeightedLRUTestSuite 是按开销限制容量的LRU缓存的测试套件
+type WeightedLRUTestSuite struct {
+	suite.Suite
+	lru *LRU[string, string]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *WeightedLRUTestSuite) SetupTest() {
+	s.lru = NewWeightedLRU(1000, func(key string, value string) int64 {
+		return int64(len(value))
+	})
+}
+
+// TestCost 测试总开销随写入、更新和删除变化
+func (s *WeightedLRUTestSuite) TestCost() {
+	s.Equal(int64(1000), s.lru.MaxCost())
+	s.NoError(s.lru.Put("a", "x"))
+	s.NoError(s.lru.Put("b", strings.Repeat("x", 100)))
+	s.Equal(int64(101), s.lru.Cost())
+
+	s.NoError(s.lru.Put("a", strings.Repeat("x", 10)))
+	s.Equal(int64(110), s.lru.Cost())
+
+	s.True(s.lru.Remove("b"))
+	s.Equal(int64(10), s.lru.Cost())
+
+	s.lru.Clear()
+	s.Equal(int64(0), s.lru.Cost())
+}
+
+// TestEvictUntilFits 测试写入大元素时从头部淘汰多个小元素
+func (s *WeightedLRUTestSuite) TestEvictUntilFits() {
+	var evicted []string
+	s.lru.OnEvict(func(key string, value string, reason cache.EvictReason) {
+		if reason == cache.EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	})
+
+	for _, key := range []string{"t1", "t2", "t3", "t4", "t5"} {
+		s.NoError(s.lru.Put(key, "tiny"))
+	}
+	s.NoError(s.lru.Put("big", strings.Repeat("x", 900)))
+	s.Equal(int64(920), s.lru.Cost())
+	s.Empty(evicted)
+
+	// 访问t1后，它成为最近访问的元素，不会被淘汰
+	s.lru.Get("t1")
+	s.NoError(s.lru.Put("huge", strings.Repeat("x", 990)))
+	s.Equal([]string{"t2", "t3", "t4", "t5", "big"}, evicted)
+	s.Equal([]string{"huge", "t1"}, s.lru.Keys())
+	s.Equal(int64(994), s.lru.Cost())
+}
+
+// TestTooLarge 测试超过开销上限的元素被拒绝，缓存保持不变
+func (s *WeightedLRUTestSuite) TestTooLarge() {
+	s.NoError(s.lru.Put("a", "small"))
+	s.NoError(s.lru.Put("b", "small"))
+
+	s.ErrorIs(s.lru.Put("c", strings.Repeat("x", 1001)), ErrTooLarge)
+	s.False(s.lru.Contains("c"))
+
+	// 更新为过大的值时保留原值
+	s.ErrorIs(s.lru.Put("a", strings.Repeat("x", 1001)), ErrTooLarge)
+	val, ok := s.lru.Peek("a")
+	s.True(ok)
+	s.Equal("small", val)
+	s.Equal(int64(10), s.lru.Cost())
+	s.Equal(2, s.lru.Len())
+
+	// 恰好等于上限的元素可以写入
+	s.NoError(s.lru.Put("c", strings.Repeat("x", 1000)))
+	s.Equal([]string{"c"}, s.lru.Keys())
+}
+
+// TestUpdateGrows 测试更新使开销变大时淘汰其他元素
+func (s *WeightedLRUTestSuite) TestUpdateGrows() {
+	s.NoError(s.lru.Put("a", strings.Repeat("x", 400)))
+	s.NoError(s.lru.Put("b", strings.Repeat("x", 400)))
+	s.NoError(s.lru.Put("a", strings.Repeat("x", 700)))
+
+	s.False(s.lru.Contains("b"))
+	s.Equal(int64(700), s.lru.Cost())
+}
+
+// TestResize 测试按开销限制容量时Resize修改开销上限
+func (s *WeightedLRUTestSuite) TestResize() {
+	s.NoError(s.lru.Put("a", strings.Repeat("x", 300)))
+	s.NoError(s.lru.Put("b", strings.Repeat("x", 300)))
+	s.NoError(s.lru.Put("c", strings.Repeat("x", 300)))
+
+	s.Equal(2, s.lru.Resize(500))
+	s.Equal(int64(500), s.lru.MaxCost())
+	s.Equal([]string{"c"}, s.lru.Keys())
+	s.Equal(int64(300), s.lru.Cost())
+}
+
+// TestZeroCost 测试开销为0或负数的元素不占用容量
+func (s *WeightedLRUTestSuite) TestZeroCost() {
+	l := NewWeightedLRU(10, func(key string, value int) int64 {
+		return int64(value)
+	})
+	s.NoError(l.Put("neg", -5))
+	s.NoError(l.Put("zero", 0))
+	s.NoError(l.Put("ten", 10))
+
+	s.Equal(3, l.Len())
+	s.Equal(int64(10), l.Cost())
+}
+
+// TestUnweighted 测试未按开销限制容量时开销为0
+func (s *WeightedLRUTestSuite) TestUnweighted() {
+	l := NewLRU[string, int](2)
+	l.Put("a", 1)
+	s.Equal(int64(0), l.Cost())
+	s.Equal(int64(0), l.MaxCost())
+}
+
+// TestWeightedLRU 运行所有按开销限制容量的LRU测试
+func TestWeightedLRU(t *testing.T) {
+	suite.Run(t, new(WeightedLRUTestSuite))
+}
@@ -0,0 +1,83 @@
+// Package ratelimit 定义限流器的公共接口
+//
+// 不同的限流算法（漏桶、令牌桶等）都实现 Limiter，调用方可以在结构体字段中
+// 使用接口类型，也可以在测试中替换为模拟实现。各实现应通过 ratelimittest
+// 中的一致性测试。
+package ratelimit
+
+import (
+	"algorithm/clock"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// InfDuration 是无效预约的等待时间，表示永远无法执行
+const InfDuration time.Duration = 1<<63 - 1
+
+var (
+	// ErrExceedsBurst 表示请求的数量超过了限流器允许的最大突发量，永远无法满足
+	ErrExceedsBurst = errors.New("ratelimit: n exceeds burst")
+	// ErrDeadline 表示需要等待的时间超过了ctx的截止时间
+	ErrDeadline = errors.New("ratelimit: wait would exceed context deadline")
+)
+
+// Limiter 限流器接口
+type Limiter interface {
+	// Allow 判断当前是否可以通过一个请求
+	Allow() bool
+	// AllowN 判断当前是否可以通过n个请求，可以时一并计入
+	AllowN(n int) bool
+	// Wait 阻塞直到可以通过一个请求，或者ctx被取消
+	Wait(ctx context.Context) error
+	// WaitN 阻塞直到可以通过n个请求，或者ctx被取消
+	WaitN(ctx context.Context, n int) error
+	// Reserve 预约一个请求
+	Reserve() Reservation
+	// ReserveN 预约n个请求，n超过 Burst 时预约无效
+	ReserveN(n int) Reservation
+	// Limit 返回长期平均每秒允许的请求数
+	Limit() float64
+	// Burst 返回一次最多可以通过的请求数
+	Burst() int
+}
+
+// Reservation 是对若干请求的预约，说明这些请求需要等待多久才符合速率限制
+type Reservation interface {
+	// OK 返回预约是否有效
+	OK() bool
+	// Delay 返回从现在起需要等待的时间，已经可以执行时返回0，预约无效时返回 InfDuration
+	Delay() time.Duration
+	// Cancel 取消尚未执行的预约，把占用的配额退还给限流器
+	Cancel()
+}
+
+// Wait 阻塞直到预约r可以执行，或者ctx被取消，供各实现的 WaitN 使用
+//
+// 预约无效时返回 ErrExceedsBurst；ctx的截止时间早于需要等待的时间时立即返回
+// ErrDeadline。返回错误时预约被取消。等待通过clk计时，而ctx的截止时间总是
+// 基于系统时间，因此与剩余的真实时间比较。
+func Wait(ctx context.Context, clk clock.Clock, r Reservation) error {
+	if !r.OK() {
+		return ErrExceedsBurst
+	}
+
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		r.Cancel()
+		return fmt.Errorf("%w: need to wait %v", ErrDeadline, delay)
+	}
+
+	select {
+	case <-clk.After(delay):
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
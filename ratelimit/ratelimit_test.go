@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"algorithm/clock"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fixedReservation 是等待时间固定的预约
+type fixedReservation struct {
+	ok       bool
+	delay    time.Duration
+	canceled int
+}
+
+func (r *fixedReservation) OK() bool             { return r.ok }
+func (r *fixedReservation) Delay() time.Duration { return r.delay }
+func (r *fixedReservation) Cancel()              { r.canceled++ }
+
+// WaitTestSuite 是 Wait 的测试套件
+type WaitTestSuite struct {
+	suite.Suite
+	clock *clock.Manual
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *WaitTestSuite) SetupTest() {
+	s.clock = clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+// TestInvalid 测试无效的预约返回 ErrExceedsBurst
+func (s *WaitTestSuite) TestInvalid() {
+	s.ErrorIs(Wait(context.Background(), s.clock, &fixedReservation{}), ErrExceedsBurst)
+}
+
+// TestNoDelay 测试不需要等待时立即返回
+func (s *WaitTestSuite) TestNoDelay() {
+	s.NoError(Wait(context.Background(), s.clock, &fixedReservation{ok: true}))
+	s.Zero(s.clock.Waiters())
+}
+
+// TestDelay 测试按时钟等待
+func (s *WaitTestSuite) TestDelay() {
+	r := &fixedReservation{ok: true, delay: time.Second}
+	done := make(chan error)
+	go func() {
+		done <- Wait(context.Background(), s.clock, r)
+	}()
+
+	s.clock.BlockUntil(1)
+	s.clock.Advance(time.Second)
+	s.NoError(<-done)
+	s.Zero(r.canceled)
+}
+
+// TestDeadline 测试截止时间不够时取消预约
+func (s *WaitTestSuite) TestDeadline() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	r := &fixedReservation{ok: true, delay: time.Hour}
+	s.ErrorIs(Wait(ctx, s.clock, r), ErrDeadline)
+	s.Equal(1, r.canceled)
+}
+
+// TestCancel 测试ctx被取消时取消预约
+func (s *WaitTestSuite) TestCancel() {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &fixedReservation{ok: true, delay: time.Hour}
+	done := make(chan error)
+	go func() {
+		done <- Wait(ctx, s.clock, r)
+	}()
+
+	s.clock.BlockUntil(1)
+	cancel()
+	s.ErrorIs(<-done, context.Canceled)
+	s.Equal(1, r.canceled)
+}
+
+// TestWait 运行所有 Wait 测试
+func TestWait(t *testing.T) {
+	suite.Run(t, new(WaitTestSuite))
+}
+
+// ReservationTestSuite 是 NewReservation 的测试套件
+type ReservationTestSuite struct {
+	suite.Suite
+	clock    *clock.Manual
+	refunded []int
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *ReservationTestSuite) SetupTest() {
+	s.clock = clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.refunded = nil
+}
+
+func (s *ReservationTestSuite) refund(now time.Time, n int) {
+	s.Equal(s.clock.Now(), now)
+	s.refunded = append(s.refunded, n)
+}
+
+// TestDelay 测试等待时间随时钟减少，到达后为0
+func (s *ReservationTestSuite) TestDelay() {
+	r := NewReservation(s.clock, 2, s.clock.Now().Add(time.Second), s.refund)
+	s.True(r.OK())
+	s.Equal(time.Second, r.Delay())
+
+	s.clock.Advance(400 * time.Millisecond)
+	s.Equal(600*time.Millisecond, r.Delay())
+	s.clock.Advance(time.Second)
+	s.Zero(r.Delay())
+}
+
+// TestCancel 测试取消只退还一次配额
+func (s *ReservationTestSuite) TestCancel() {
+	r := NewReservation(s.clock, 2, s.clock.Now().Add(time.Second), s.refund)
+	r.Cancel()
+	r.Cancel()
+	s.Equal([]int{2}, s.refunded)
+}
+
+// TestCancelAfterDue 测试到达预约时刻后取消不起作用
+func (s *ReservationTestSuite) TestCancelAfterDue() {
+	r := NewReservation(s.clock, 2, s.clock.Now().Add(time.Second), s.refund)
+	s.clock.Advance(time.Second)
+	r.Cancel()
+	s.Empty(s.refunded)
+
+	// 不占用配额的预约取消时不调用refund
+	NewReservation(s.clock, 0, s.clock.Now().Add(time.Second), nil).Cancel()
+}
+
+// TestInvalid 测试无效的预约永远无法执行，取消不起作用
+func (s *ReservationTestSuite) TestInvalid() {
+	r := InvalidReservation()
+	s.False(r.OK())
+	s.Equal(InfDuration, r.Delay())
+	r.Cancel()
+}
+
+// TestReservation 运行所有 NewReservation 测试
+func TestReservation(t *testing.T) {
+	suite.Run(t, new(ReservationTestSuite))
+}
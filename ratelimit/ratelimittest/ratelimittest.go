@@ -0,0 +1,217 @@
+// Package ratelimittest 提供所有 ratelimit.Limiter 实现都应通过的一致性测试
+//
+// 各算法在自己的测试中调用 Run，传入按速率、突发量和时钟构造限流器的函数即可：
+//
+//	func TestConformance(t *testing.T) {
+//		ratelimittest.Run(t, func(limit float64, burst int, clk clock.Clock) ratelimit.Limiter {
+//			return New(int64(burst), limit, WithClock(clk))
+//		})
+//	}
+//
+// 测试使用 clock.Manual 推进时间，不会真实等待。
+package ratelimittest
+
+import (
+	"algorithm/clock"
+	"algorithm/ratelimit"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// 一致性测试使用的速率和突发量：每秒10个请求，每个请求间隔100毫秒
+const (
+	limit    = 10
+	burst    = 5
+	interval = time.Second / limit
+)
+
+// Suite 限流器的一致性测试套件
+type Suite struct {
+	suite.Suite
+	New     func(limit float64, burst int, clk clock.Clock) ratelimit.Limiter
+	clock   *clock.Manual
+	limiter ratelimit.Limiter
+}
+
+// Run 对newLimiter构造的限流器运行一致性测试
+func Run(t *testing.T, newLimiter func(limit float64, burst int, clk clock.Clock) ratelimit.Limiter) {
+	suite.Run(t, &Suite{New: newLimiter})
+}
+
+// SetupTest 在每个测试用例之前执行，创建一个使用手动时钟的限流器
+func (s *Suite) SetupTest() {
+	s.clock = clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.limiter = s.New(limit, burst, s.clock)
+}
+
+// exhaust 用完所有突发量
+func (s *Suite) exhaust() {
+	s.Require().True(s.limiter.AllowN(burst))
+	s.Require().False(s.limiter.Allow())
+}
+
+// TestLimitAndBurst 测试速率和突发量
+func (s *Suite) TestLimitAndBurst() {
+	s.Equal(float64(limit), s.limiter.Limit())
+	s.Equal(burst, s.limiter.Burst())
+}
+
+// TestBurst 测试新建的限流器可以一次通过等于突发量的请求
+func (s *Suite) TestBurst() {
+	for range burst {
+		s.True(s.limiter.Allow())
+	}
+	s.False(s.limiter.Allow())
+	s.False(s.limiter.AllowN(2))
+
+	// 0个请求总是可以通过
+	s.True(s.limiter.AllowN(0))
+}
+
+// TestExceedsBurst 测试超过突发量的请求永远无法满足，且不占用配额
+func (s *Suite) TestExceedsBurst() {
+	s.False(s.limiter.AllowN(burst + 1))
+
+	r := s.limiter.ReserveN(burst + 1)
+	s.False(r.OK())
+	s.Equal(ratelimit.InfDuration, r.Delay())
+	r.Cancel()
+
+	s.ErrorIs(s.limiter.WaitN(context.Background(), burst+1), ratelimit.ErrExceedsBurst)
+	s.True(s.limiter.AllowN(burst))
+}
+
+// TestRefill 测试配额随时间恢复
+func (s *Suite) TestRefill() {
+	s.exhaust()
+
+	s.clock.Advance(interval - time.Millisecond)
+	s.False(s.limiter.Allow())
+
+	s.clock.Advance(time.Millisecond)
+	s.True(s.limiter.Allow())
+	s.False(s.limiter.Allow())
+
+	// 经过足够长的时间后恢复全部突发量，但不会超过突发量
+	s.clock.Advance(time.Hour)
+	s.True(s.limiter.AllowN(burst))
+	s.False(s.limiter.Allow())
+}
+
+// TestLongRunRate 测试长期通过的请求数不超过速率加突发量
+func (s *Suite) TestLongRunRate() {
+	const seconds = 10
+	allowed := 0
+	for range seconds * 100 {
+		if s.limiter.Allow() {
+			allowed++
+		}
+		s.clock.Advance(10 * time.Millisecond)
+	}
+
+	s.LessOrEqual(allowed, burst+limit*seconds)
+	s.GreaterOrEqual(allowed, limit*seconds)
+}
+
+// TestReserve 测试预约的等待时间，预约会占用配额
+func (s *Suite) TestReserve() {
+	r := s.limiter.Reserve()
+	s.True(r.OK())
+	s.Zero(r.Delay())
+
+	s.limiter.AllowN(burst - 1)
+	r = s.limiter.Reserve()
+	s.True(r.OK())
+	s.InDelta(interval, r.Delay(), float64(time.Millisecond))
+
+	// 后续预约排在后面
+	s.InDelta(2*interval, s.limiter.Reserve().Delay(), float64(time.Millisecond))
+
+	s.clock.Advance(r.Delay())
+	s.Zero(r.Delay())
+	s.False(s.limiter.Allow())
+}
+
+// TestReserveCancel 测试取消预约会退还配额，重复取消不起作用
+func (s *Suite) TestReserveCancel() {
+	s.exhaust()
+
+	first := s.limiter.Reserve()
+	second := s.limiter.Reserve()
+	s.InDelta(2*interval, second.Delay(), float64(time.Millisecond))
+
+	first.Cancel()
+	first.Cancel()
+	s.InDelta(2*interval, s.limiter.Reserve().Delay(), float64(time.Millisecond))
+}
+
+// TestWait 测试等待直到可以通过请求
+func (s *Suite) TestWait() {
+	ctx := context.Background()
+	s.NoError(s.limiter.Wait(ctx))
+	s.NoError(s.limiter.WaitN(ctx, 0))
+	s.Zero(s.clock.Waiters())
+
+	s.limiter.AllowN(burst - 1)
+	done := make(chan error)
+	go func() {
+		done <- s.limiter.Wait(ctx)
+	}()
+
+	s.clock.BlockUntil(1)
+	s.clock.Advance(interval + time.Millisecond)
+	s.NoError(<-done)
+}
+
+// TestWaitCancel 测试ctx被取消时停止等待并退还配额
+func (s *Suite) TestWaitCancel() {
+	s.exhaust()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.limiter.Wait(ctx)
+	}()
+
+	s.clock.BlockUntil(1)
+	cancel()
+	s.ErrorIs(<-done, context.Canceled)
+	s.InDelta(interval, s.limiter.Reserve().Delay(), float64(time.Millisecond))
+
+	// 已取消的ctx直接返回错误
+	s.ErrorIs(s.limiter.Wait(ctx), context.Canceled)
+}
+
+// TestWaitDeadline 测试需要等待的时间超过截止时间时立即返回
+func (s *Suite) TestWaitDeadline() {
+	// 截止时间基于系统时间，远短于需要等待的时间
+	slow := s.New(1.0/3600, 1, s.clock)
+	slow.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	s.ErrorIs(slow.Wait(ctx), ratelimit.ErrDeadline)
+	s.Zero(s.clock.Waiters())
+	s.InDelta(time.Hour, slow.Reserve().Delay(), float64(time.Millisecond))
+}
+
+// TestConcurrent 测试并发请求通过的数量不超过突发量
+func (s *Suite) TestConcurrent() {
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for range 50 {
+		wg.Go(func() {
+			if s.limiter.Allow() {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	s.Equal(int32(burst), allowed.Load())
+}
@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"algorithm/clock"
+	"sync"
+	"time"
+)
+
+// reservation 是 NewReservation 和 InvalidReservation 返回的预约
+type reservation struct {
+	clock  clock.Clock
+	n      int
+	ok     bool
+	at     time.Time // 请求可以执行的时刻
+	refund func(now time.Time, n int)
+
+	once sync.Once
+}
+
+// NewReservation 创建n个请求在at时刻可以执行的有效预约，供各实现的 ReserveN 使用
+//
+// 在at之前取消时调用一次refund，把n个请求占用的配额退还给限流器；at已经到达时
+// 请求视为已经执行，取消不起作用。n不大于0时预约不占用配额，refund可以为nil。
+func NewReservation(clk clock.Clock, n int, at time.Time, refund func(now time.Time, n int)) Reservation {
+	return &reservation{clock: clk, n: n, ok: true, at: at, refund: refund}
+}
+
+// InvalidReservation 返回永远无法执行的无效预约，用于请求数量超过突发量的情况
+func InvalidReservation() Reservation {
+	return &reservation{}
+}
+
+// OK 返回预约是否有效
+func (r *reservation) OK() bool {
+	return r.ok
+}
+
+// Delay 返回从现在起需要等待的时间，已经可以执行时返回0
+//
+// 预约无效时返回 InfDuration，表示永远无法执行。
+func (r *reservation) Delay() time.Duration {
+	if !r.ok {
+		return InfDuration
+	}
+
+	return max(r.at.Sub(r.clock.Now()), 0)
+}
+
+// Cancel 取消预约，退还尚未执行的请求占用的配额
+//
+// 预约的时刻已经到达时请求视为已经执行，取消不起作用；重复取消也不起作用。
+func (r *reservation) Cancel() {
+	if !r.ok || r.n <= 0 {
+		return
+	}
+
+	now := r.clock.Now()
+	if !now.Before(r.at) {
+		return
+	}
+
+	r.once.Do(func() {
+		r.refund(now, r.n)
+	})
+}
@@ -0,0 +1,377 @@
+// Package s3fifo 实现了并发安全的S3-FIFO缓存
+//
+// S3-FIFO使用三个FIFO队列：约占10%容量的小队列S、占其余容量的主队列M，
+// 以及只保存键的幽灵队列G。每个元素带有一个最大为3的访问计数，命中时只增加计数，
+// 不移动元素，因此读操作只需要读锁。
+//
+//   - 新元素进入S；如果它的键在G中，说明不久前刚被淘汰，直接进入M。
+//   - 从S淘汰时，访问次数超过1的元素移入M，其余的被淘汰并把键记入G。
+//     大多数只访问一次的元素在S中就被快速淘汰，不会进入M。
+//   - 从M淘汰时，访问计数大于0的元素计数减1并重新放到M的尾部，否则被淘汰。
+//
+// 所有队列的头部是最早进入的一端，尾部是最新进入的一端。
+package s3fifo
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"sync"
+	"sync/atomic"
+)
+
+// maxFreq 是访问计数的上限
+const maxFreq = 3
+
+// 元素所在的队列
+const (
+	inSmall = iota
+	inMain
+	inGhost
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	freq  atomic.Int32
+	where int
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[*entry[K, V]]
+
+type queue[K comparable, V any] = double_linked_list.DoubleLinkedList[*entry[K, V]]
+
+// S3FIFO 并发安全的S3-FIFO缓存
+type S3FIFO[K comparable, V any] struct {
+	mu       sync.RWMutex
+	items    map[K]*node[K, V] // 包括幽灵队列中的键
+	small    *queue[K, V]
+	main     *queue[K, V]
+	ghost    *queue[K, V]
+	capacity int
+	smallCap int
+	mainCap  int
+	onEvict  func(key K, value V, reason cache.EvictReason)
+	pending  cache.PendingEvictions[K, V] // 持锁期间离开缓存、尚未回调的元素
+	stats    cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*S3FIFO[string, int])(nil)
+
+// New 根据构造选项创建S3-FIFO缓存，容量通过 cache.WithCapacity 指定
+//
+// 通过 cache.WithOnEvict 指定的回调在锁外执行。
+func New[K comparable, V any](opts ...cache.Option) *S3FIFO[K, V] {
+	o := cache.NewOptions(opts...)
+
+	c := &S3FIFO[K, V]{
+		items:   make(map[K]*node[K, V]),
+		small:   double_linked_list.NewDoubleLinkedList[*entry[K, V]](),
+		main:    double_linked_list.NewDoubleLinkedList[*entry[K, V]](),
+		ghost:   double_linked_list.NewDoubleLinkedList[*entry[K, V]](),
+		onEvict: cache.OnEvict[K, V](o),
+	}
+	c.setCapacity(o.Capacity)
+
+	return c
+}
+
+// NewS3FIFO 创建容量为capacity的S3-FIFO缓存
+func NewS3FIFO[K comparable, V any](capacity int, opts ...cache.Option) *S3FIFO[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// setCapacity 按总容量划分小队列和主队列的大小，幽灵队列与主队列一样大
+func (c *S3FIFO[K, V]) setCapacity(capacity int) {
+	c.capacity = max(capacity, 0)
+	c.smallCap = min(max(c.capacity/10, 1), c.capacity)
+	c.mainCap = c.capacity - c.smallCap
+}
+
+// Get 返回key对应的值并增加访问计数，只持有读锁
+func (c *S3FIFO[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var zero V
+	n, ok := c.resident(key)
+	if !ok {
+		c.stats.Miss()
+		return zero, false
+	}
+
+	c.stats.Hit()
+	e := n.Val
+	if f := e.freq.Load(); f < maxFreq {
+		// 并发命中时可能少计几次，计数只用于近似判断
+		e.freq.CompareAndSwap(f, f+1)
+	}
+
+	return e.value, true
+}
+
+// Peek 返回key对应的值，不增加访问计数
+func (c *S3FIFO[K, V]) Peek(key K) (V, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var zero V
+	n, ok := c.resident(key)
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否在缓存中，幽灵队列中的键不算在内
+func (c *S3FIFO[K, V]) Contains(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, ok := c.resident(key)
+	return ok
+}
+
+// Put 写入元素，已存在的元素更新值并视为一次访问
+func (c *S3FIFO[K, V]) Put(key K, value V) error {
+	c.mu.Lock()
+	defer c.unlock()
+
+	n, ok := c.items[key]
+	if ok && n.Val.where != inGhost {
+		e := n.Val
+		old := e.value
+		e.value = value
+		if f := e.freq.Load(); f < maxFreq {
+			e.freq.Store(f + 1)
+		}
+		c.evicted(key, old, cache.EvictReplaced)
+		return nil
+	}
+
+	// 先删除幽灵键，避免腾出空间时它作为幽灵队列中最早的键被丢弃
+	if ok {
+		c.ghost.Remove(n)
+		delete(c.items, key)
+	}
+
+	c.stats.Insert()
+	if c.capacity == 0 {
+		c.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	if c.size() >= c.capacity {
+		c.evictOne()
+	}
+
+	e := &entry[K, V]{key: key, value: value}
+	if ok {
+		e.where = inMain
+		c.items[key] = c.main.Append(e)
+	} else {
+		e.where = inSmall
+		c.items[key] = c.small.Append(e)
+	}
+
+	return nil
+}
+
+// evictOne 淘汰一个元素：小队列达到其容量或主队列为空时从小队列淘汰，否则从主队列淘汰
+func (c *S3FIFO[K, V]) evictOne() bool {
+	if c.small.Size() >= c.smallCap || c.main.Size() == 0 {
+		if c.evictSmall() {
+			return true
+		}
+	}
+
+	return c.evictMain()
+}
+
+// evictSmall 从小队列淘汰一个元素，沿途把访问次数超过1的元素移入主队列
+//
+// 移入导致主队列超出容量时改为从主队列淘汰。小队列中的元素全部被移走时返回false。
+func (c *S3FIFO[K, V]) evictSmall() bool {
+	for {
+		n := c.small.Front()
+		if n == nil {
+			return false
+		}
+
+		e := n.Val
+		c.small.Remove(n)
+		if e.freq.Load() > 1 {
+			e.freq.Store(0)
+			e.where = inMain
+			c.items[e.key] = c.main.Append(e)
+			if c.main.Size() > c.mainCap {
+				return c.evictMain()
+			}
+			continue
+		}
+
+		e.where = inGhost
+		c.items[e.key] = c.ghost.Append(e)
+		c.evicted(e.key, e.value, cache.EvictCapacity)
+		var zero V
+		e.value = zero
+		c.trimGhost()
+		return true
+	}
+}
+
+// evictMain 从主队列淘汰一个元素，访问计数大于0的元素计数减1后重新放到尾部
+func (c *S3FIFO[K, V]) evictMain() bool {
+	for {
+		n := c.main.Front()
+		if n == nil {
+			return false
+		}
+
+		e := n.Val
+		if f := e.freq.Load(); f > 0 {
+			e.freq.Store(f - 1)
+			c.main.MoveToTail(n)
+			continue
+		}
+
+		c.main.Remove(n)
+		delete(c.items, e.key)
+		c.evicted(e.key, e.value, cache.EvictCapacity)
+		return true
+	}
+}
+
+// trimGhost 幽灵队列超出主队列的容量时丢弃最早的键
+func (c *S3FIFO[K, V]) trimGhost() {
+	for c.ghost.Size() > max(c.mainCap, 1) {
+		e, _ := c.ghost.RemoveHead()
+		delete(c.items, e.key)
+	}
+}
+
+// Remove 删除缓存中的元素；幽灵队列中的键也会被清除，但返回false
+func (c *S3FIFO[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	n, ok := c.items[key]
+	if !ok {
+		return false
+	}
+
+	e := n.Val
+	c.queue(e.where).Remove(n)
+	delete(c.items, key)
+	if e.where == inGhost {
+		return false
+	}
+
+	c.evicted(e.key, e.value, cache.EvictRemoved)
+	return true
+}
+
+// Len 返回缓存中元素的数量，不包括幽灵队列
+func (c *S3FIFO[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.size()
+}
+
+// Keys 返回缓存中的所有键，先主队列后小队列，各自从最新到最早进入排列
+func (c *S3FIFO[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, c.size())
+	for _, q := range []*queue[K, V]{c.main, c.small} {
+		for n := range q.Backward() {
+			keys = append(keys, n.Val.key)
+		}
+	}
+
+	return keys
+}
+
+// Clear 删除所有元素和幽灵键，每个元素都会以 cache.EvictRemoved 触发回调
+func (c *S3FIFO[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	small, main := c.small, c.main
+	c.items = make(map[K]*node[K, V])
+	c.small = double_linked_list.NewDoubleLinkedList[*entry[K, V]]()
+	c.main = double_linked_list.NewDoubleLinkedList[*entry[K, V]]()
+	c.ghost = double_linked_list.NewDoubleLinkedList[*entry[K, V]]()
+
+	for _, q := range []*queue[K, V]{small, main} {
+		for n := range q.Nodes() {
+			c.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+		}
+	}
+}
+
+// Resize 修改容量并重新划分各队列，容量缩小时按S3-FIFO的规则淘汰多出的元素，
+// 返回淘汰的数量
+func (c *S3FIFO[K, V]) Resize(capacity int) int {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.setCapacity(capacity)
+	evicted := 0
+	for c.size() > c.capacity && c.evictOne() {
+		evicted++
+	}
+	c.trimGhost()
+
+	return evicted
+}
+
+// Stats 返回统计信息，Peek 和 Contains 不计入命中或未命中
+func (c *S3FIFO[K, V]) Stats() cache.Stats {
+	return c.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (c *S3FIFO[K, V]) ResetStats() {
+	c.stats.Reset()
+}
+
+// size 返回小队列和主队列中元素数量之和，调用者需持有锁
+func (c *S3FIFO[K, V]) size() int {
+	return c.small.Size() + c.main.Size()
+}
+
+// resident 返回在小队列或主队列中的节点
+func (c *S3FIFO[K, V]) resident(key K) (*node[K, V], bool) {
+	n, ok := c.items[key]
+	if !ok || n.Val.where == inGhost {
+		return nil, false
+	}
+
+	return n, true
+}
+
+func (c *S3FIFO[K, V]) queue(where int) *queue[K, V] {
+	switch where {
+	case inSmall:
+		return c.small
+	case inMain:
+		return c.main
+	default:
+		return c.ghost
+	}
+}
+
+// unlock 释放写锁，并在锁外执行持锁期间积攒的淘汰回调
+func (c *S3FIFO[K, V]) unlock() {
+	c.pending.Unlock(&c.mu, c.onEvict)
+}
+
+func (c *S3FIFO[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	c.stats.Evict(reason)
+	if c.onEvict != nil {
+		c.pending.Add(key, value, reason)
+	}
+}
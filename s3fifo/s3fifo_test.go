@@ -0,0 +1,157 @@
+package s3fifo
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// S3FIFOTestSuite 是S3-FIFO缓存的测试套件
+type S3FIFOTestSuite struct {
+	suite.Suite
+	c *S3FIFO[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *S3FIFOTestSuite) SetupTest() {
+	s.c = NewS3FIFO[string, int](10)
+}
+
+// TestQueues 测试各队列的容量划分
+func (s *S3FIFOTestSuite) TestQueues() {
+	s.Equal(1, s.c.smallCap)
+	s.Equal(9, s.c.mainCap)
+
+	c := NewS3FIFO[string, int](1000)
+	s.Equal(100, c.smallCap)
+	s.Equal(900, c.mainCap)
+
+	c = NewS3FIFO[string, int](1)
+	s.Equal(1, c.smallCap)
+	s.Equal(0, c.mainCap)
+}
+
+// TestSmallToGhost 测试小队列中只访问一次的元素被淘汰并记入幽灵队列
+func (s *S3FIFOTestSuite) TestSmallToGhost() {
+	for i := range 11 {
+		s.NoError(s.c.Put(strconv.Itoa(i), i))
+	}
+
+	s.False(s.c.Contains("0"))
+	s.Equal(inGhost, s.c.items["0"].Val.where)
+	s.Equal(10, s.c.Len())
+
+	// 幽灵队列中的键再次写入时直接进入主队列
+	s.NoError(s.c.Put("0", 10))
+	s.Equal(inMain, s.c.items["0"].Val.where)
+	s.Equal(inGhost, s.c.items["1"].Val.where)
+	val, ok := s.c.Peek("0")
+	s.True(ok)
+	s.Equal(10, val)
+}
+
+// TestSmallToMain 测试小队列中访问次数超过1的元素移入主队列
+func (s *S3FIFOTestSuite) TestSmallToMain() {
+	for i := range 10 {
+		s.NoError(s.c.Put(strconv.Itoa(i), i))
+	}
+	s.c.Get("0")
+	s.c.Get("0")
+	s.c.Get("1")
+
+	s.NoError(s.c.Put("10", 10))
+	s.Equal(inMain, s.c.items["0"].Val.where)
+	s.Equal(int32(0), s.c.items["0"].Val.freq.Load())
+
+	// 只访问过一次的元素仍被淘汰
+	s.Equal(inGhost, s.c.items["1"].Val.where)
+}
+
+// TestMainReinsert 测试从主队列淘汰时访问计数大于0的元素被重新放到尾部
+func (s *S3FIFOTestSuite) TestMainReinsert() {
+	c := NewS3FIFO[string, int](4)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		s.NoError(c.Put(key, 0))
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		c.Get(key)
+		c.Get(key)
+	}
+
+	// a、b、c移入主队列，d被淘汰
+	s.NoError(c.Put("e", 0))
+	s.Equal([]string{"c", "b", "a", "e"}, c.Keys())
+	c.Get("a")
+
+	// 先淘汰小队列中的e，再淘汰主队列中计数为0的b
+	s.Equal(2, c.Resize(2))
+	s.Equal([]string{"a", "c"}, c.Keys())
+
+	// c的计数为0被淘汰，a的计数已在上一轮清零但仍是最新的元素
+	s.Equal(1, c.Resize(1))
+	s.Equal([]string{"a"}, c.Keys())
+}
+
+// TestZipfHitRatio 测试在Zipf分布的访问序列上命中率高于LRU
+func (s *S3FIFOTestSuite) TestZipfHitRatio() {
+	trace := cachetest.ZipfTrace(42, 1.1, 100000, 200000)
+	s3 := cachetest.HitRatio(NewS3FIFO[int, int](1000), trace)
+	plain := cachetest.HitRatio(lru.NewLRU[int, int](1000), trace)
+	s.T().Logf("zipf: s3fifo=%.4f lru=%.4f", s3, plain)
+	s.Greater(s3, plain)
+}
+
+// TestConcurrentAccess 测试并发读写，需配合 -race 运行
+func (s *S3FIFOTestSuite) TestConcurrentAccess() {
+	c := NewS3FIFO[string, int](64)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				key := strconv.Itoa((g*1000 + i) % 100)
+				if _, ok := c.Get(key); !ok {
+					c.Put(key, i)
+				}
+				if i%10 == 0 {
+					c.Remove(key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	s.LessOrEqual(c.Len(), 64)
+	st := c.Stats()
+	s.Equal(uint64(8000), st.Hits+st.Misses)
+}
+
+// TestS3FIFO 运行所有S3-FIFO测试
+func TestS3FIFO(t *testing.T) {
+	suite.Run(t, new(S3FIFOTestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
+
+// BenchmarkConcurrentGet 比较并发命中时S3-FIFO与加锁的LRU的吞吐量
+func BenchmarkConcurrentGet(b *testing.B) {
+	b.Run("s3fifo", func(b *testing.B) {
+		cachetest.BenchmarkConcurrentGet(b, func(capacity int) cache.Cache[int, int] {
+			return NewS3FIFO[int, int](capacity)
+		})
+	})
+	b.Run("lru", func(b *testing.B) {
+		cachetest.BenchmarkConcurrentGet(b, func(capacity int) cache.Cache[int, int] {
+			return lru.NewSyncLRU[int, int](capacity)
+		})
+	})
+}
@@ -0,0 +1,245 @@
+// Package sieve 实现了并发安全的SIEVE缓存
+//
+// SIEVE只维护一个FIFO队列，每个元素带有一个访问标记。命中时只设置标记，
+// 不移动元素，因此读操作只需要读锁，多个 Get 可以并行执行。淘汰时由一个
+// 指针（hand）从最早进入的一端向最新的一端扫描：遇到带标记的元素就清除标记并跳过，
+// 遇到没有标记的元素就将其淘汰，指针停在下一个元素上，到达末尾后回到队列头部。
+//
+// 队列的头部是最早进入的一端，尾部是最新进入的一端。
+package sieve
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"sync"
+	"sync/atomic"
+)
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	visited atomic.Bool
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[*entry[K, V]]
+
+// SIEVE 并发安全的SIEVE缓存
+type SIEVE[K comparable, V any] struct {
+	mu       sync.RWMutex
+	items    map[K]*node[K, V]
+	queue    *double_linked_list.DoubleLinkedList[*entry[K, V]]
+	hand     *node[K, V] // 下一次淘汰开始检查的元素，nil表示从头部开始
+	capacity int
+	onEvict  func(key K, value V, reason cache.EvictReason)
+	pending  cache.PendingEvictions[K, V] // 持锁期间离开缓存、尚未回调的元素
+	stats    cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*SIEVE[string, int])(nil)
+
+// New 根据构造选项创建SIEVE缓存，容量通过 cache.WithCapacity 指定
+//
+// 通过 cache.WithOnEvict 指定的回调在锁外执行。
+func New[K comparable, V any](opts ...cache.Option) *SIEVE[K, V] {
+	o := cache.NewOptions(opts...)
+
+	return &SIEVE[K, V]{
+		items:    make(map[K]*node[K, V]),
+		queue:    double_linked_list.NewDoubleLinkedList[*entry[K, V]](),
+		capacity: max(o.Capacity, 0),
+		onEvict:  cache.OnEvict[K, V](o),
+	}
+}
+
+// NewSIEVE 创建容量为capacity的SIEVE缓存
+func NewSIEVE[K comparable, V any](capacity int, opts ...cache.Option) *SIEVE[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// Get 返回key对应的值并设置访问标记，只持有读锁
+func (s *SIEVE[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var zero V
+	n, ok := s.items[key]
+	if !ok {
+		s.stats.Miss()
+		return zero, false
+	}
+
+	s.stats.Hit()
+	e := n.Val
+	if !e.visited.Load() {
+		e.visited.Store(true)
+	}
+
+	return e.value, true
+}
+
+// Peek 返回key对应的值，不设置访问标记
+func (s *SIEVE[K, V]) Peek(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var zero V
+	n, ok := s.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否存在，不设置访问标记
+func (s *SIEVE[K, V]) Contains(key K) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.items[key]
+	return ok
+}
+
+// Put 写入元素，新元素进入队列尾部，已存在的元素更新值并设置访问标记
+func (s *SIEVE[K, V]) Put(key K, value V) error {
+	s.mu.Lock()
+	defer s.unlock()
+
+	if n, ok := s.items[key]; ok {
+		old := n.Val.value
+		n.Val.value = value
+		n.Val.visited.Store(true)
+		s.evicted(key, old, cache.EvictReplaced)
+		return nil
+	}
+
+	s.stats.Insert()
+	if s.capacity == 0 {
+		s.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	if len(s.items) >= s.capacity {
+		s.evictOne()
+	}
+	s.items[key] = s.queue.Append(&entry[K, V]{key: key, value: value})
+
+	return nil
+}
+
+// evictOne 从hand开始找到第一个没有访问标记的元素并淘汰，沿途清除访问标记
+func (s *SIEVE[K, V]) evictOne() bool {
+	n := s.hand
+	if n == nil {
+		n = s.queue.Front()
+	}
+
+	for n != nil && n.Val.visited.Load() {
+		n.Val.visited.Store(false)
+		if n = s.queue.Next(n); n == nil {
+			n = s.queue.Front()
+		}
+	}
+	if n == nil {
+		return false
+	}
+
+	s.hand = s.queue.Next(n)
+	e := n.Val
+	s.queue.Remove(n)
+	delete(s.items, e.key)
+	s.evicted(e.key, e.value, cache.EvictCapacity)
+
+	return true
+}
+
+func (s *SIEVE[K, V]) Remove(key K) bool {
+	s.mu.Lock()
+	defer s.unlock()
+
+	n, ok := s.items[key]
+	if !ok {
+		return false
+	}
+
+	if s.hand == n {
+		s.hand = s.queue.Next(n)
+	}
+	e := n.Val
+	s.queue.Remove(n)
+	delete(s.items, key)
+	s.evicted(e.key, e.value, cache.EvictRemoved)
+
+	return true
+}
+
+func (s *SIEVE[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.items)
+}
+
+// Keys 按从最新到最早进入的顺序返回所有键
+func (s *SIEVE[K, V]) Keys() []K {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]K, 0, len(s.items))
+	for n := range s.queue.Backward() {
+		keys = append(keys, n.Val.key)
+	}
+
+	return keys
+}
+
+// Clear 删除所有元素，每个元素都会以 cache.EvictRemoved 触发回调
+func (s *SIEVE[K, V]) Clear() {
+	s.mu.Lock()
+	defer s.unlock()
+
+	old := s.queue
+	s.items = make(map[K]*node[K, V])
+	s.queue = double_linked_list.NewDoubleLinkedList[*entry[K, V]]()
+	s.hand = nil
+
+	for n := range old.Nodes() {
+		s.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+	}
+}
+
+// Resize 修改容量，容量缩小时按SIEVE的规则立即淘汰多出的元素，返回淘汰的数量
+func (s *SIEVE[K, V]) Resize(capacity int) int {
+	s.mu.Lock()
+	defer s.unlock()
+
+	s.capacity = max(capacity, 0)
+	evicted := 0
+	for len(s.items) > s.capacity && s.evictOne() {
+		evicted++
+	}
+
+	return evicted
+}
+
+// Stats 返回统计信息，Peek 和 Contains 不计入命中或未命中
+func (s *SIEVE[K, V]) Stats() cache.Stats {
+	return s.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (s *SIEVE[K, V]) ResetStats() {
+	s.stats.Reset()
+}
+
+// unlock 释放写锁，并在锁外执行持锁期间积攒的淘汰回调
+func (s *SIEVE[K, V]) unlock() {
+	s.pending.Unlock(&s.mu, s.onEvict)
+}
+
+func (s *SIEVE[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	s.stats.Evict(reason)
+	if s.onEvict != nil {
+		s.pending.Add(key, value, reason)
+	}
+}
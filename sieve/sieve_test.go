@@ -0,0 +1,139 @@
+package sieve
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SIEVETestSuite 是SIEVE缓存的测试套件
+type SIEVETestSuite struct {
+	suite.Suite
+	sieve *SIEVE[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *SIEVETestSuite) SetupTest() {
+	s.sieve = NewSIEVE[string, int](3)
+}
+
+// TestHand 测试指针跳过并清除带访问标记的元素，淘汰第一个没有标记的元素
+func (s *SIEVETestSuite) TestHand() {
+	s.NoError(s.sieve.Put("a", 1))
+	s.NoError(s.sieve.Put("b", 2))
+	s.NoError(s.sieve.Put("c", 3))
+	s.sieve.Get("a")
+
+	s.NoError(s.sieve.Put("d", 4))
+	s.Equal([]string{"d", "c", "a"}, s.sieve.Keys())
+	s.False(s.sieve.items["a"].Val.visited.Load())
+	s.Equal("c", s.sieve.hand.Val.key)
+
+	// 指针停在c，继续从c开始检查
+	s.NoError(s.sieve.Put("e", 5))
+	s.Equal([]string{"e", "d", "a"}, s.sieve.Keys())
+	s.Equal("d", s.sieve.hand.Val.key)
+}
+
+// TestHandWrap 测试所有元素都有访问标记时指针绕回头部，淘汰最早进入的元素
+func (s *SIEVETestSuite) TestHandWrap() {
+	s.NoError(s.sieve.Put("a", 1))
+	s.NoError(s.sieve.Put("b", 2))
+	s.NoError(s.sieve.Put("c", 3))
+	s.sieve.Get("a")
+	s.sieve.Get("b")
+	s.sieve.Get("c")
+
+	s.NoError(s.sieve.Put("d", 4))
+	s.Equal([]string{"d", "c", "b"}, s.sieve.Keys())
+	s.Equal("b", s.sieve.hand.Val.key)
+}
+
+// TestRemoveHand 测试删除指针所在的元素时指针后移
+func (s *SIEVETestSuite) TestRemoveHand() {
+	s.NoError(s.sieve.Put("a", 1))
+	s.NoError(s.sieve.Put("b", 2))
+	s.NoError(s.sieve.Put("c", 3))
+	s.NoError(s.sieve.Put("d", 4))
+	s.Equal("b", s.sieve.hand.Val.key)
+
+	s.True(s.sieve.Remove("b"))
+	s.Equal("c", s.sieve.hand.Val.key)
+	s.NoError(s.sieve.Put("e", 5))
+	s.NoError(s.sieve.Put("f", 6))
+	s.Equal([]string{"f", "e", "d"}, s.sieve.Keys())
+}
+
+// TestPeekNoVisit 测试Peek和Contains不设置访问标记
+func (s *SIEVETestSuite) TestPeekNoVisit() {
+	s.NoError(s.sieve.Put("a", 1))
+	val, ok := s.sieve.Peek("a")
+	s.True(ok)
+	s.Equal(1, val)
+	s.True(s.sieve.Contains("a"))
+	s.False(s.sieve.items["a"].Val.visited.Load())
+}
+
+// TestZipfHitRatio 测试在Zipf分布的访问序列上命中率不低于LRU
+func (s *SIEVETestSuite) TestZipfHitRatio() {
+	trace := cachetest.ZipfTrace(42, 1.1, 100000, 200000)
+	sieve := cachetest.HitRatio(NewSIEVE[int, int](1000), trace)
+	plain := cachetest.HitRatio(lru.NewLRU[int, int](1000), trace)
+	s.T().Logf("zipf: sieve=%.4f lru=%.4f", sieve, plain)
+	s.Greater(sieve, plain)
+}
+
+// TestConcurrentAccess 测试并发读写，需配合 -race 运行
+func (s *SIEVETestSuite) TestConcurrentAccess() {
+	c := NewSIEVE[string, int](64)
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				key := strconv.Itoa((g*1000 + i) % 100)
+				if _, ok := c.Get(key); !ok {
+					c.Put(key, i)
+				}
+				if i%10 == 0 {
+					c.Remove(key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	s.LessOrEqual(c.Len(), 64)
+	st := c.Stats()
+	s.Equal(uint64(8000), st.Hits+st.Misses)
+}
+
+// TestSIEVE 运行所有SIEVE测试
+func TestSIEVE(t *testing.T) {
+	suite.Run(t, new(SIEVETestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
+
+// BenchmarkConcurrentGet 比较并发命中时SIEVE与加锁的LRU的吞吐量
+func BenchmarkConcurrentGet(b *testing.B) {
+	b.Run("sieve", func(b *testing.B) {
+		cachetest.BenchmarkConcurrentGet(b, func(capacity int) cache.Cache[int, int] {
+			return NewSIEVE[int, int](capacity)
+		})
+	})
+	b.Run("lru", func(b *testing.B) {
+		cachetest.BenchmarkConcurrentGet(b, func(capacity int) cache.Cache[int, int] {
+			return lru.NewSyncLRU[int, int](capacity)
+		})
+	})
+}
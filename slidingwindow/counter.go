@@ -0,0 +1,90 @@
+package slidingwindow
+
+import (
+	"sync"
+	"time"
+)
+
+// Counter 滑动窗口计数器限流器
+//
+// 时间被划分为长度为window的固定窗口，只记录当前窗口和上一个窗口通过的请求数。
+// 判断时假设上一个窗口的请求均匀分布，按它与滚动窗口重叠的比例计入：
+//
+//	估计值 = 上一个窗口的计数 × (window - 当前窗口已经过的时间) / window + 当前窗口的计数
+//
+// 估计值加上本次的请求数不超过limit时通过。由于上一个窗口的请求未必均匀分布，
+// 突发流量下滚动窗口内通过的请求数可能超过limit，需要严格保证时使用 Log。
+type Counter struct {
+	mu     sync.Mutex
+	opts   options
+	limit  int
+	window time.Duration
+	start  int64 // 当前窗口的开始时间（Unix纳秒），为window的整数倍
+	cur    int   // 当前窗口通过的请求数
+	prev   int   // 上一个窗口通过的请求数
+}
+
+// NewCounter 创建任意window时长内大约最多通过limit个请求的滑动窗口计数器限流器，
+// window必须为正数
+func NewCounter(limit int, window time.Duration, opts ...Option) *Counter {
+	checkWindow(window)
+	return &Counter{
+		opts:   newOptions(opts...),
+		limit:  max(limit, 0),
+		window: window,
+	}
+}
+
+// Limit 返回长期平均每秒允许的请求数
+func (c *Counter) Limit() float64 {
+	return float64(c.limit) / c.window.Seconds()
+}
+
+// Burst 返回一次最多可以通过的请求数，即窗口内允许的请求数
+func (c *Counter) Burst() int {
+	return c.limit
+}
+
+// Allow 判断当前是否可以通过一个请求
+func (c *Counter) Allow() bool {
+	return c.AllowN(1)
+}
+
+// AllowN 判断当前是否可以通过n个请求，可以时计入当前窗口
+func (c *Counter) AllowN(n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.opts.clock.Now().UnixNano()
+	c.advance(now)
+
+	elapsed := float64(now-c.start) / float64(c.window)
+	estimate := float64(c.prev)*(1-elapsed) + float64(c.cur)
+	if estimate+float64(n) > float64(c.limit) {
+		return false
+	}
+
+	c.cur += n
+	return true
+}
+
+// advance 滚动到now所在的固定窗口，时钟回拨时保持当前窗口不变
+func (c *Counter) advance(now int64) {
+	window := int64(c.window)
+	start := now - now%window
+	switch {
+	case start <= c.start:
+		return
+	case start == c.start+window:
+		c.prev = c.cur
+	default:
+		c.prev = 0
+	}
+
+	c.cur = 0
+	c.start = start
+}
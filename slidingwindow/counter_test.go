@@ -0,0 +1,149 @@
+package slidingwindow
+
+import (
+	"algorithm/clock"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// CounterTestSuite 是滑动窗口计数器限流器的测试套件
+type CounterTestSuite struct {
+	suite.Suite
+	clock   *clock.Manual
+	counter *Counter
+}
+
+// SetupTest 在每个测试用例之前执行，创建每分钟最多100个请求的限流器
+func (s *CounterTestSuite) SetupTest() {
+	s.clock = clock.NewManual(start)
+	s.counter = NewCounter(100, time.Minute, WithClock(s.clock))
+}
+
+// TestLimitAndBurst 测试速率和突发量
+func (s *CounterTestSuite) TestLimitAndBurst() {
+	s.InDelta(100.0/60, s.counter.Limit(), 1e-9)
+	s.Equal(100, s.counter.Burst())
+}
+
+// TestBurst 测试窗口内最多通过limit个请求
+func (s *CounterTestSuite) TestBurst() {
+	s.False(s.counter.AllowN(101))
+	s.True(s.counter.AllowN(100))
+	s.False(s.counter.Allow())
+	s.True(s.counter.AllowN(0))
+}
+
+// TestBoundary 测试跨过固定窗口边界时，上一个窗口的计数按重叠比例计入
+func (s *CounterTestSuite) TestBoundary() {
+	// 上一个窗口末尾的突发在新窗口开始时仍全部计入，不会出现固定窗口的两倍突发
+	s.clock.Advance(59 * time.Second)
+	s.True(s.counter.AllowN(100))
+	s.clock.Advance(time.Second)
+	s.False(s.counter.Allow())
+
+	// 新窗口过半时上一个窗口只计入一半
+	s.clock.Advance(30 * time.Second)
+	s.True(s.counter.AllowN(50))
+	s.False(s.counter.Allow())
+
+	// 再下一个窗口开始时，上一个窗口的50个请求全部计入
+	s.clock.Advance(30 * time.Second)
+	s.False(s.counter.AllowN(51))
+	s.True(s.counter.AllowN(50))
+
+	// 空闲超过一个完整窗口后计数清零
+	s.clock.Advance(2 * time.Minute)
+	s.True(s.counter.AllowN(100))
+}
+
+// TestWeight 测试上一个窗口的权重随时间线性下降
+func (s *CounterTestSuite) TestWeight() {
+	s.True(s.counter.AllowN(60))
+
+	// 新窗口经过了15秒，上一个窗口计入 60×45/60=45 个
+	s.clock.Advance(75 * time.Second)
+	s.False(s.counter.AllowN(56))
+	s.True(s.counter.AllowN(55))
+	s.False(s.counter.Allow())
+
+	// 再经过15秒，上一个窗口计入30个，当前窗口55个
+	s.clock.Advance(15 * time.Second)
+	s.True(s.counter.AllowN(15))
+	s.False(s.counter.Allow())
+}
+
+// TestUniformAccuracy 测试请求均匀到达时，计数器与精确的日志限流器通过的数量接近
+func (s *CounterTestSuite) TestUniformAccuracy() {
+	log := NewLog(100, time.Minute, WithClock(s.clock))
+
+	// 每秒到达4个请求，是限制的2.4倍，持续10分钟
+	counted, logged := 0, 0
+	for range 10 * 60 * 4 {
+		if s.counter.Allow() {
+			counted++
+		}
+		if log.Allow() {
+			logged++
+		}
+		s.clock.Advance(250 * time.Millisecond)
+	}
+
+	s.T().Logf("counter=%d log=%d", counted, logged)
+	s.InDelta(logged, counted, float64(logged)*0.02)
+	s.LessOrEqual(counted, 100*11)
+}
+
+// TestClusteredError 测试请求集中在上一个窗口末尾时的估算误差
+//
+// 计数器假设上一个窗口的请求均匀分布，请求集中在窗口末尾时会低估它们的占用，
+// 滚动窗口内通过的请求数可能超过limit；日志限流器则不会。
+func (s *CounterTestSuite) TestClusteredError() {
+	log := NewLog(100, time.Minute, WithClock(s.clock))
+
+	s.clock.Advance(59 * time.Second)
+	s.True(s.counter.AllowN(100))
+	s.True(log.AllowN(100))
+
+	s.clock.Advance(31 * time.Second)
+	s.True(s.counter.AllowN(50))
+	s.False(log.Allow())
+}
+
+// TestClockRollback 测试时钟回拨时保持当前窗口，不会清零计数
+func (s *CounterTestSuite) TestClockRollback() {
+	s.True(s.counter.AllowN(100))
+	s.clock.Advance(-time.Hour)
+	s.False(s.counter.Allow())
+}
+
+// TestInvalid 测试limit为0和非法的窗口
+func (s *CounterTestSuite) TestInvalid() {
+	c := NewCounter(0, time.Second, WithClock(s.clock))
+	s.False(c.Allow())
+	s.Panics(func() { NewCounter(1, -time.Second) })
+}
+
+// TestConcurrent 测试并发请求通过的数量正好为limit
+func (s *CounterTestSuite) TestConcurrent() {
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for range 200 {
+		wg.Go(func() {
+			if s.counter.Allow() {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	s.Equal(int32(100), allowed.Load())
+}
+
+// TestCounter 运行所有滑动窗口计数器测试
+func TestCounter(t *testing.T) {
+	suite.Run(t, new(CounterTestSuite))
+}
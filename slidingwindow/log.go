@@ -0,0 +1,93 @@
+// Package slidingwindow 实现了滑动窗口限流器
+//
+// 两种限流器都以“长度为window的滚动时间段内最多通过limit个请求”为目标，
+// 区别在于精度和开销：
+//
+//   - Log 记录窗口内每个通过的请求的时间戳，内存占用为 O(limit)，严格保证任意
+//     长度为window的滚动时间段内最多通过limit个请求；
+//   - Counter 只记录当前和上一个固定窗口的计数，按上一个窗口与滚动窗口重叠的
+//     比例估算，内存占用为 O(1)。它只是近似：请求集中在上一个窗口末尾时会被低估，
+//     滚动时间段内通过的请求数可能超过limit。
+//
+// 二者提供与 leakybucket 相同的 Allow/AllowN 接口，通过 WithClock 注入时钟。
+package slidingwindow
+
+import (
+	"sync"
+	"time"
+)
+
+// Log 滑动窗口日志限流器
+//
+// 通过的请求的时间戳保存在容量为limit的环形缓冲区中，请求在time时刻通过后，
+// 在 (time, time+window) 内一直占用一个名额，到time+window时刻释放。
+type Log struct {
+	mu     sync.Mutex
+	opts   options
+	limit  int
+	window time.Duration
+	times  []int64 // 环形缓冲区，保存通过的请求的时间（Unix纳秒），从head开始按时间升序
+	head   int
+	size   int
+}
+
+// NewLog 创建任意window时长内最多通过limit个请求的滑动窗口日志限流器，window必须为正数
+func NewLog(limit int, window time.Duration, opts ...Option) *Log {
+	checkWindow(window)
+	limit = max(limit, 0)
+	return &Log{
+		opts:   newOptions(opts...),
+		limit:  limit,
+		window: window,
+		times:  make([]int64, limit),
+	}
+}
+
+// Limit 返回长期平均每秒允许的请求数
+func (l *Log) Limit() float64 {
+	return float64(l.limit) / l.window.Seconds()
+}
+
+// Burst 返回一次最多可以通过的请求数，即窗口内允许的请求数
+func (l *Log) Burst() int {
+	return l.limit
+}
+
+// Allow 判断当前是否可以通过一个请求
+func (l *Log) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN 判断当前是否可以通过n个请求，可以时记录n个请求的时间
+func (l *Log) AllowN(n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.opts.clock.Now().UnixNano()
+	l.expire(now)
+	if l.size+n > l.limit {
+		return false
+	}
+
+	for range n {
+		l.times[(l.head+l.size)%l.limit] = now
+		l.size++
+	}
+
+	return true
+}
+
+// expire 删除已经离开窗口的时间戳
+//
+// 时钟回拨时新记录的时间戳可能早于之前的记录，此时缓冲区不再严格有序，
+// 较早的记录会被后面较晚的记录挡住，稍晚才释放，不会多放行请求。
+func (l *Log) expire(now int64) {
+	for l.size > 0 && l.times[l.head] <= now-int64(l.window) {
+		l.head = (l.head + 1) % l.limit
+		l.size--
+	}
+}
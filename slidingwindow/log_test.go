@@ -0,0 +1,134 @@
+package slidingwindow
+
+import (
+	"algorithm/clock"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// start 是测试时钟的起始时间，正好是分钟的整数倍
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// LogTestSuite 是滑动窗口日志限流器的测试套件
+type LogTestSuite struct {
+	suite.Suite
+	clock *clock.Manual
+	log   *Log
+}
+
+// SetupTest 在每个测试用例之前执行，创建每分钟最多100个请求的限流器
+func (s *LogTestSuite) SetupTest() {
+	s.clock = clock.NewManual(start)
+	s.log = NewLog(100, time.Minute, WithClock(s.clock))
+}
+
+// TestLimitAndBurst 测试速率和突发量
+func (s *LogTestSuite) TestLimitAndBurst() {
+	s.InDelta(100.0/60, s.log.Limit(), 1e-9)
+	s.Equal(100, s.log.Burst())
+}
+
+// TestBurst 测试窗口内最多通过limit个请求
+func (s *LogTestSuite) TestBurst() {
+	s.False(s.log.AllowN(101))
+	s.True(s.log.AllowN(100))
+	s.False(s.log.Allow())
+	s.True(s.log.AllowN(0))
+}
+
+// TestBoundary 测试请求正好在一个窗口之后释放名额
+func (s *LogTestSuite) TestBoundary() {
+	s.True(s.log.AllowN(100))
+
+	s.clock.Advance(time.Minute - time.Nanosecond)
+	s.False(s.log.Allow())
+
+	s.clock.Advance(time.Nanosecond)
+	s.True(s.log.AllowN(100))
+	s.False(s.log.Allow())
+}
+
+// TestRolling 测试窗口随时间滚动，只释放已经离开窗口的请求
+func (s *LogTestSuite) TestRolling() {
+	s.True(s.log.AllowN(50))
+	s.clock.Advance(30 * time.Second)
+	s.True(s.log.AllowN(50))
+	s.False(s.log.Allow())
+
+	// 第一批请求离开窗口，第二批仍在窗口内
+	s.clock.Advance(30 * time.Second)
+	s.False(s.log.AllowN(51))
+	s.True(s.log.AllowN(50))
+
+	s.clock.Advance(30 * time.Second)
+	s.True(s.log.AllowN(50))
+	s.False(s.log.Allow())
+}
+
+// TestExact 测试与逐个统计滚动窗口的参考实现结果完全一致
+func (s *LogTestSuite) TestExact() {
+	r := rand.New(rand.NewPCG(7, 7))
+	var allowed []time.Time
+
+	for i := range 5000 {
+		s.clock.Advance(time.Duration(r.IntN(2000)) * time.Millisecond)
+		now := s.clock.Now()
+		n := r.IntN(5)
+
+		inWindow := 0
+		for _, t := range allowed {
+			if t.After(now.Add(-time.Minute)) {
+				inWindow++
+			}
+		}
+		want := inWindow+n <= 100
+
+		s.Require().Equal(want, s.log.AllowN(n), "request %d", i)
+		if want {
+			for range n {
+				allowed = append(allowed, now)
+			}
+		}
+	}
+}
+
+// TestZeroLimit 测试limit为0时拒绝所有请求
+func (s *LogTestSuite) TestZeroLimit() {
+	l := NewLog(0, time.Second, WithClock(s.clock))
+	s.False(l.Allow())
+	s.True(l.AllowN(0))
+	s.Panics(func() { NewLog(1, 0) })
+}
+
+// TestClockRollback 测试时钟回拨时不会多放行请求
+func (s *LogTestSuite) TestClockRollback() {
+	s.True(s.log.AllowN(100))
+	s.clock.Advance(-time.Hour)
+	s.False(s.log.Allow())
+}
+
+// TestConcurrent 测试并发请求通过的数量正好为limit
+func (s *LogTestSuite) TestConcurrent() {
+	var wg sync.WaitGroup
+	var allowed atomic.Int32
+	for range 200 {
+		wg.Go(func() {
+			if s.log.Allow() {
+				allowed.Add(1)
+			}
+		})
+	}
+	wg.Wait()
+
+	s.Equal(int32(100), allowed.Load())
+}
+
+// TestLog 运行所有滑动窗口日志测试
+func TestLog(t *testing.T) {
+	suite.Run(t, new(LogTestSuite))
+}
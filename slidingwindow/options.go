@@ -0,0 +1,35 @@
+package slidingwindow
+
+import (
+	"algorithm/clock"
+	"time"
+)
+
+type options struct {
+	clock clock.Clock
+}
+
+// Option 是滑动窗口限流器的构造选项
+type Option func(o *options)
+
+// WithClock 指定限流器使用的时钟，默认使用 clock.Real
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+func newOptions(opts ...Option) options {
+	o := options{clock: clock.Real}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+func checkWindow(window time.Duration) {
+	if window <= 0 {
+		panic("slidingwindow: window must be positive")
+	}
+}
@@ -0,0 +1,24 @@
+package slru
+
+import "algorithm/cache"
+
+// defaultProtectedRatio 是保护段默认占总容量的比例
+const defaultProtectedRatio = 0.8
+
+type protectedRatioKey struct{}
+
+// WithProtectedRatio 指定保护段占总容量的比例，取值范围为[0, 1]，默认为0.8
+func WithProtectedRatio(ratio float64) cache.Option {
+	return func(o *cache.Options) {
+		o.SetValue(protectedRatioKey{}, ratio)
+	}
+}
+
+func protectedRatio(o *cache.Options) float64 {
+	ratio, ok := o.Value(protectedRatioKey{}).(float64)
+	if !ok || ratio < 0 || ratio > 1 {
+		return defaultProtectedRatio
+	}
+
+	return ratio
+}
@@ -0,0 +1,284 @@
+// Package slru 实现了分段LRU（Segmented LRU）缓存
+//
+// 缓存分为试用段（probationary）和保护段（protected）两个LRU链表。新元素进入试用段，
+// 在试用段中再次被访问时晋升到保护段；保护段超出容量时，其中最久未访问的元素
+// 被降级回试用段的尾部。淘汰总是从试用段开始，因此只访问一次的扫描流量
+// 只会在试用段中相互淘汰，不会冲刷保护段中被访问过多次的元素。
+//
+// 所有链表的头部是最久未访问的一端，尾部是最近访问的一端。
+package slru
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"iter"
+)
+
+// 元素所在的分段
+const (
+	inProbation = iota
+	inProtected
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	where int
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[entry[K, V]]
+
+type list[K comparable, V any] = double_linked_list.DoubleLinkedList[entry[K, V]]
+
+type SLRU[K comparable, V any] struct {
+	items        map[K]*node[K, V]
+	probation    *list[K, V]
+	protected    *list[K, V]
+	capacity     int
+	protectedCap int
+	ratio        float64 // 保护段占总容量的比例
+	onEvict      func(key K, value V, reason cache.EvictReason)
+	stats        cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*SLRU[string, int])(nil)
+
+// New 根据构造选项创建分段LRU缓存，容量通过 cache.WithCapacity 指定，
+// 保护段的比例通过 WithProtectedRatio 指定
+func New[K comparable, V any](opts ...cache.Option) *SLRU[K, V] {
+	o := cache.NewOptions(opts...)
+
+	s := &SLRU[K, V]{
+		items:     make(map[K]*node[K, V]),
+		probation: double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		protected: double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		ratio:     protectedRatio(o),
+		onEvict:   cache.OnEvict[K, V](o),
+	}
+	s.setCapacity(o.Capacity)
+
+	return s
+}
+
+// NewSLRU 创建容量为capacity的分段LRU缓存
+func NewSLRU[K comparable, V any](capacity int, opts ...cache.Option) *SLRU[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// setCapacity 按总容量和比例划分保护段的大小
+func (s *SLRU[K, V]) setCapacity(capacity int) {
+	s.capacity = max(capacity, 0)
+	s.protectedCap = min(int(float64(s.capacity)*s.ratio), s.capacity)
+}
+
+// Get 返回key对应的值，试用段中的元素晋升到保护段，保护段中的元素被移动到尾部
+func (s *SLRU[K, V]) Get(key K) (V, bool) {
+	var zero V
+	n, ok := s.items[key]
+	if !ok {
+		s.stats.Miss()
+		return zero, false
+	}
+
+	s.stats.Hit()
+	n = s.touch(n)
+	return n.Val.value, true
+}
+
+// Peek 返回key对应的值，不影响淘汰顺序
+func (s *SLRU[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	n, ok := s.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否存在，不影响淘汰顺序
+func (s *SLRU[K, V]) Contains(key K) bool {
+	_, ok := s.items[key]
+	return ok
+}
+
+// Put 写入元素，新元素进入试用段的尾部，已存在的元素更新值并视为一次访问
+func (s *SLRU[K, V]) Put(key K, value V) error {
+	if n, ok := s.items[key]; ok {
+		old := n.Val.value
+		n = s.touch(n)
+		n.Val.value = value
+		s.evicted(key, old, cache.EvictReplaced)
+		return nil
+	}
+
+	s.stats.Insert()
+	if s.capacity == 0 {
+		s.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	if s.Len() >= s.capacity {
+		s.evictOne()
+	}
+	s.items[key] = s.probation.Append(entry[K, V]{key: key, value: value, where: inProbation})
+
+	return nil
+}
+
+// touch 处理一次访问，返回元素的新节点
+func (s *SLRU[K, V]) touch(n *node[K, V]) *node[K, V] {
+	if n.Val.where == inProtected {
+		s.protected.MoveToTail(n)
+		return n
+	}
+
+	// 保护段容量为0时退化为普通的LRU
+	if s.protectedCap == 0 {
+		s.probation.MoveToTail(n)
+		return n
+	}
+
+	e := n.Val
+	s.probation.Remove(n)
+	e.where = inProtected
+	n = s.protected.Append(e)
+	s.items[e.key] = n
+	s.demote()
+
+	return n
+}
+
+// demote 保护段超出容量时，把最久未访问的元素降级到试用段的尾部
+func (s *SLRU[K, V]) demote() {
+	for s.protected.Size() > s.protectedCap {
+		e, err := s.protected.RemoveHead()
+		if err != nil {
+			return
+		}
+
+		e.where = inProbation
+		s.items[e.key] = s.probation.Append(e)
+	}
+}
+
+// evictOne 淘汰试用段中最久未访问的元素，试用段为空时淘汰保护段中的
+func (s *SLRU[K, V]) evictOne() bool {
+	victims := s.probation
+	if victims.Size() == 0 {
+		victims = s.protected
+	}
+
+	e, err := victims.RemoveHead()
+	if err != nil {
+		return false
+	}
+
+	delete(s.items, e.key)
+	s.evicted(e.key, e.value, cache.EvictCapacity)
+	return true
+}
+
+func (s *SLRU[K, V]) Remove(key K) bool {
+	n, ok := s.items[key]
+	if !ok {
+		return false
+	}
+
+	e := n.Val
+	s.list(e.where).Remove(n)
+	delete(s.items, key)
+	s.evicted(e.key, e.value, cache.EvictRemoved)
+
+	return true
+}
+
+func (s *SLRU[K, V]) Len() int {
+	return len(s.items)
+}
+
+// Size 与 Len 相同
+func (s *SLRU[K, V]) Size() int {
+	return len(s.items)
+}
+
+// Keys 返回所有键，顺序与 All 相同
+func (s *SLRU[K, V]) Keys() []K {
+	keys := make([]K, 0, len(s.items))
+	for key := range s.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// All 返回遍历所有元素的迭代器，先保护段后试用段，各自从最近到最久访问排列，
+// 遍历不影响淘汰顺序
+func (s *SLRU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, l := range []*list[K, V]{s.protected, s.probation} {
+			for n := range l.Backward() {
+				if !yield(n.Val.key, n.Val.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clear 删除所有元素，每个元素都会以 cache.EvictRemoved 触发回调
+func (s *SLRU[K, V]) Clear() {
+	lists := []*list[K, V]{s.probation, s.protected}
+	s.items = make(map[K]*node[K, V])
+	s.probation = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	s.protected = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+
+	for _, l := range lists {
+		for n := range l.Nodes() {
+			s.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+		}
+	}
+}
+
+// Resize 修改容量，保护段按原比例调整；容量缩小时先从试用段淘汰，返回淘汰的数量
+func (s *SLRU[K, V]) Resize(capacity int) int {
+	s.setCapacity(capacity)
+
+	evicted := 0
+	for s.Len() > s.capacity && s.evictOne() {
+		evicted++
+	}
+	s.demote()
+
+	return evicted
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+func (s *SLRU[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	s.onEvict = fn
+}
+
+// Stats 返回统计信息，Peek 和 Contains 不计入命中或未命中
+func (s *SLRU[K, V]) Stats() cache.Stats {
+	return s.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (s *SLRU[K, V]) ResetStats() {
+	s.stats.Reset()
+}
+
+func (s *SLRU[K, V]) list(where int) *list[K, V] {
+	if where == inProtected {
+		return s.protected
+	}
+
+	return s.probation
+}
+
+func (s *SLRU[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	s.stats.Evict(reason)
+	if s.onEvict != nil {
+		s.onEvict(key, value, reason)
+	}
+}
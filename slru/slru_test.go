@@ -0,0 +1,171 @@
+package slru
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SLRUTestSuite 是分段LRU缓存的测试套件
+type SLRUTestSuite struct {
+	suite.Suite
+	slru *SLRU[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *SLRUTestSuite) SetupTest() {
+	s.slru = NewSLRU[string, int](5)
+}
+
+// TestSegments 测试保护段的容量划分
+func (s *SLRUTestSuite) TestSegments() {
+	s.Equal(4, s.slru.protectedCap)
+
+	c := NewSLRU[string, int](10, WithProtectedRatio(0.5))
+	s.Equal(5, c.protectedCap)
+
+	// 非法比例使用默认值
+	c = NewSLRU[string, int](10, WithProtectedRatio(2))
+	s.Equal(8, c.protectedCap)
+}
+
+// TestPromoteAndDemote 测试试用段中的元素被命中后晋升，保护段满时最久未访问的元素被降级
+func (s *SLRUTestSuite) TestPromoteAndDemote() {
+	for i := range 5 {
+		s.NoError(s.slru.Put(strconv.Itoa(i), i))
+		s.Equal(inProbation, s.slru.items[strconv.Itoa(i)].Val.where)
+	}
+
+	for i := range 5 {
+		s.slru.Get(strconv.Itoa(i))
+	}
+
+	// 保护段容量为4，最早晋升的0被降级到试用段
+	s.Equal(inProbation, s.slru.items["0"].Val.where)
+	s.Equal(4, s.slru.protected.Size())
+	s.Equal([]string{"4", "3", "2", "1", "0"}, s.slru.Keys())
+}
+
+// TestEvictProbationFirst 测试优先淘汰试用段中的元素
+func (s *SLRUTestSuite) TestEvictProbationFirst() {
+	s.NoError(s.slru.Put("hot", 0))
+	s.slru.Get("hot")
+	for i := range 10 {
+		s.NoError(s.slru.Put(strconv.Itoa(i), i))
+	}
+
+	s.True(s.slru.Contains("hot"))
+	s.Equal(5, s.slru.Len())
+	s.Equal(5, s.slru.Size())
+
+	// 试用段为空时淘汰保护段中的元素
+	c := NewSLRU[string, int](2, WithProtectedRatio(1))
+	s.NoError(c.Put("a", 1))
+	s.NoError(c.Put("b", 2))
+	c.Get("a")
+	c.Get("b")
+	s.NoError(c.Put("c", 3))
+	s.Equal([]string{"b", "c"}, c.Keys())
+}
+
+// TestNoProtected 测试保护段容量为0时退化为LRU
+func (s *SLRUTestSuite) TestNoProtected() {
+	c := NewSLRU[string, int](2, WithProtectedRatio(0))
+	s.NoError(c.Put("a", 1))
+	s.NoError(c.Put("b", 2))
+	c.Get("a")
+	s.NoError(c.Put("c", 3))
+	s.Equal([]string{"c", "a"}, c.Keys())
+}
+
+// TestResize 测试缩小容量时先淘汰试用段并按比例调整保护段
+func (s *SLRUTestSuite) TestResize() {
+	for i := range 5 {
+		s.NoError(s.slru.Put(strconv.Itoa(i), i))
+	}
+	s.slru.Get("3")
+	s.slru.Get("4")
+
+	s.Equal(3, s.slru.Resize(2))
+	s.Equal(1, s.slru.protectedCap)
+	s.Equal([]string{"4", "3"}, s.slru.Keys())
+	s.Equal(inProbation, s.slru.items["3"].Val.where)
+}
+
+// TestScanResistance 测试周期性的扫描不会冲刷热点数据，而LRU会
+func (s *SLRUTestSuite) TestScanResistance() {
+	const capacity = 100
+	c := NewSLRU[string, int](capacity)
+	l := lru.NewLRU[string, int](capacity)
+
+	slruHits, lruHits, total := 0, 0, 0
+	access := func(key string, hot bool) {
+		if _, ok := c.Get(key); ok && hot {
+			slruHits++
+		} else if !ok {
+			c.Put(key, 0)
+		}
+		if _, ok := l.Get(key); ok && hot {
+			lruHits++
+		} else if !ok {
+			l.Put(key, 0)
+		}
+		if hot {
+			total++
+		}
+	}
+
+	// 每一轮访问热点数据两次，再扫描一遍冷数据
+	scan := 0
+	for range 10 {
+		for range 2 {
+			for i := range 50 {
+				access("hot"+strconv.Itoa(i), true)
+			}
+		}
+		for range capacity {
+			access("scan"+strconv.Itoa(scan), false)
+			scan++
+		}
+	}
+
+	slruRatio := float64(slruHits) / float64(total)
+	lruRatio := float64(lruHits) / float64(total)
+	s.T().Logf("hot hit ratio: slru=%.2f lru=%.2f", slruRatio, lruRatio)
+	s.Greater(slruRatio, 0.9)
+	s.InDelta(0.5, lruRatio, 0.01)
+}
+
+// TestStats 测试命中、未命中和淘汰的统计
+func (s *SLRUTestSuite) TestStats() {
+	for i := range 6 {
+		s.NoError(s.slru.Put(strconv.Itoa(i), i))
+	}
+	s.slru.Get("5")
+	s.slru.Get("0")
+	s.NoError(s.slru.Put("5", 50))
+
+	st := s.slru.Stats()
+	s.Equal(uint64(1), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(6), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(5, st.Size)
+}
+
+// TestSLRU 运行所有分段LRU测试
+func TestSLRU(t *testing.T) {
+	suite.Run(t, new(SLRUTestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
@@ -0,0 +1,182 @@
+package tinylfu
+
+import "math/bits"
+
+const (
+	sketchDepth = 4  // Count-Min Sketch 的行数
+	maxCounter  = 15 // 计数器上限，与4位计数器相同
+	doorkeeperK = 3  // 守门员布隆过滤器的哈希函数个数
+)
+
+// bloom 是一个简单的布隆过滤器
+type bloom struct {
+	bits []uint64
+	mask uint64
+}
+
+func newBloom(size int) *bloom {
+	n := nextPowerOfTwo(size)
+	return &bloom{
+		bits: make([]uint64, (n+63)/64),
+		mask: uint64(n - 1),
+	}
+}
+
+// add 加入一个哈希值，返回它之前是否可能已经存在
+func (b *bloom) add(h uint64) bool {
+	h1, h2 := h, h>>32|1
+	existed := true
+	for i := range uint64(doorkeeperK) {
+		idx := (h1 + i*h2) & b.mask
+		word, bit := idx/64, uint64(1)<<(idx%64)
+		if b.bits[word]&bit == 0 {
+			existed = false
+			b.bits[word] |= bit
+		}
+	}
+
+	return existed
+}
+
+func (b *bloom) contains(h uint64) bool {
+	h1, h2 := h, h>>32|1
+	for i := range uint64(doorkeeperK) {
+		idx := (h1 + i*h2) & b.mask
+		if b.bits[idx/64]&(uint64(1)<<(idx%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *bloom) reset() {
+	clear(b.bits)
+}
+
+// sketch 是带守门员的 Count-Min Sketch 频率估计器
+//
+// 键第一次出现时只记录在守门员布隆过滤器中，再次出现才会累加到计数器上，
+// 这样大量只出现一次的键不会占用计数器。累加次数达到采样大小后，
+// 所有计数器减半并清空守门员，使频率估计随时间衰减。
+type sketch struct {
+	rows       [sketchDepth][]uint8
+	mask       uint64
+	doorkeeper *bloom
+	additions  int
+	sampleSize int
+}
+
+func newSketch(capacity int) *sketch {
+	width := sketchWidth(capacity)
+	s := &sketch{
+		mask:       uint64(width - 1),
+		doorkeeper: newBloom(width * 4),
+		sampleSize: sampleSize(capacity),
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+func sketchWidth(capacity int) int {
+	return nextPowerOfTwo(max(capacity, 16))
+}
+
+func sampleSize(capacity int) int {
+	return 10 * max(capacity, 16)
+}
+
+// resize 返回适合新容量的 sketch，宽度不变时只调整采样大小并返回s本身
+//
+// 宽度变化时重新分配计数器和守门员。旧的计数器无法按新的宽度重新映射，
+// 因此只迁移hashes（通常是缓存中现有的键）的频率估计，其余的历史被丢弃。
+func (s *sketch) resize(capacity int, hashes []uint64) *sketch {
+	if sketchWidth(capacity) == len(s.rows[0]) {
+		s.sampleSize = sampleSize(capacity)
+		s.additions = min(s.additions, s.sampleSize-1)
+		return s
+	}
+
+	n := newSketch(capacity)
+	for _, h := range hashes {
+		count := s.count(h)
+		for row := range n.rows {
+			idx := n.index(h, row)
+			n.rows[row][idx] = max(n.rows[row][idx], uint8(count))
+		}
+		if s.doorkeeper.contains(h) {
+			n.doorkeeper.add(h)
+		}
+	}
+	n.additions = min(s.additions, n.sampleSize-1)
+
+	return n
+}
+
+// index 返回哈希值在第row行中的位置
+func (s *sketch) index(h uint64, row int) uint64 {
+	// 每行使用不同的旋转，得到近似独立的哈希
+	return bits.RotateLeft64(h, row*16) * 0x9e3779b97f4a7c15 >> 32 & s.mask
+}
+
+// increment 记录一次访问
+func (s *sketch) increment(h uint64) {
+	if !s.doorkeeper.add(h) {
+		return
+	}
+
+	for row := range s.rows {
+		idx := s.index(h, row)
+		if s.rows[row][idx] < maxCounter {
+			s.rows[row][idx]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// estimate 返回访问频率的估计值
+func (s *sketch) estimate(h uint64) int {
+	freq := s.count(h)
+	if s.doorkeeper.contains(h) {
+		freq++
+	}
+
+	return freq
+}
+
+// count 返回计数器中的最小值，不包括守门员
+func (s *sketch) count(h uint64) int {
+	freq := maxCounter
+	for row := range s.rows {
+		freq = min(freq, int(s.rows[row][s.index(h, row)]))
+	}
+
+	return freq
+}
+
+// reset 将所有计数器减半并清空守门员
+func (s *sketch) reset() {
+	for row := range s.rows {
+		for i := range s.rows[row] {
+			s.rows[row][i] >>= 1
+		}
+	}
+
+	s.doorkeeper.reset()
+	s.additions /= 2
+}
+
+func nextPowerOfTwo(n int) int {
+	if n <= 1 {
+		return 1
+	}
+
+	return 1 << bits.Len(uint(n-1))
+}
@@ -0,0 +1,101 @@
+package tinylfu
+
+import (
+	"hash/maphash"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// SketchTestSuite 是频率估计器的测试套件
+type SketchTestSuite struct {
+	suite.Suite
+	seed   maphash.Seed
+	sketch *sketch
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *SketchTestSuite) SetupTest() {
+	s.seed = maphash.MakeSeed()
+	s.sketch = newSketch(64)
+}
+
+func (s *SketchTestSuite) hash(key string) uint64 {
+	return maphash.String(s.seed, key)
+}
+
+// TestDoorkeeper 测试第一次访问只记录在守门员中
+func (s *SketchTestSuite) TestDoorkeeper() {
+	h := s.hash("key1")
+	s.Equal(0, s.sketch.estimate(h))
+
+	s.sketch.increment(h)
+	s.Equal(1, s.sketch.estimate(h))
+	s.Equal(0, s.sketch.additions)
+
+	s.sketch.increment(h)
+	s.Equal(2, s.sketch.estimate(h))
+	s.Equal(1, s.sketch.additions)
+}
+
+// TestCounterLimit 测试计数器不超过上限
+func (s *SketchTestSuite) TestCounterLimit() {
+	h := s.hash("key1")
+	for range 100 {
+		s.sketch.increment(h)
+	}
+
+	s.Equal(maxCounter+1, s.sketch.estimate(h))
+}
+
+// TestReset 测试累加次数达到采样大小后计数器减半
+func (s *SketchTestSuite) TestReset() {
+	s.sketch.sampleSize = 10
+	hot := s.hash("hot")
+	for range 9 {
+		s.sketch.increment(hot)
+	}
+	s.Equal(9, s.sketch.estimate(hot))
+	s.Equal(8, s.sketch.additions)
+
+	// 第10次累加触发减半：计数10减半为5，守门员被清空
+	s.sketch.increment(hot)
+	s.sketch.increment(hot)
+	s.Equal(5, s.sketch.estimate(hot))
+	s.False(s.sketch.doorkeeper.contains(hot))
+	s.Equal(5, s.sketch.additions)
+}
+
+// TestResize 测试按新容量重新分配时保留给定键的频率
+func (s *SketchTestSuite) TestResize() {
+	hot, cold := s.hash("hot"), s.hash("cold")
+	for range 5 {
+		s.sketch.increment(hot)
+		s.sketch.increment(cold)
+	}
+
+	// 宽度不变时只调整采样大小
+	same := s.sketch.resize(50, nil)
+	s.Same(s.sketch, same)
+	s.Equal(500, same.sampleSize)
+
+	grown := s.sketch.resize(10000, []uint64{hot})
+	s.Len(grown.rows[0], 16384)
+	s.Equal(100000, grown.sampleSize)
+	s.Equal(5, grown.estimate(hot))
+	s.Zero(grown.estimate(cold))
+}
+
+// TestNextPowerOfTwo 测试向上取整到2的幂
+func (s *SketchTestSuite) TestNextPowerOfTwo() {
+	s.Equal(1, nextPowerOfTwo(0))
+	s.Equal(1, nextPowerOfTwo(1))
+	s.Equal(2, nextPowerOfTwo(2))
+	s.Equal(4, nextPowerOfTwo(3))
+	s.Equal(1024, nextPowerOfTwo(1000))
+}
+
+// TestSketch 运行所有频率估计器测试
+func TestSketch(t *testing.T) {
+	suite.Run(t, new(SketchTestSuite))
+}
@@ -0,0 +1,337 @@
+// Package tinylfu 实现了 W-TinyLFU 缓存
+//
+// 缓存分为两部分：一个约占1%容量的窗口LRU，以及由试用段（probation）和
+// 保护段（protected）组成的分段LRU主区域。新元素先进入窗口，被挤出窗口时
+// 与主区域的淘汰候选者比较访问频率，频率更高者留下，从而阻止只访问一次的
+// 元素挤掉有用的元素。访问频率由带守门员的 Count-Min Sketch 估计，
+// 并周期性减半以适应访问模式的变化。
+//
+// 所有链表的头部是最久未访问的一端，尾部是最近访问的一端。
+package tinylfu
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"hash/maphash"
+	"iter"
+)
+
+// 元素所在的区域
+const (
+	inWindow = iota
+	inProbation
+	inProtected
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	where int
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[entry[K, V]]
+
+type list[K comparable, V any] = double_linked_list.DoubleLinkedList[entry[K, V]]
+
+type TinyLFU[K comparable, V any] struct {
+	items        map[K]*node[K, V]
+	window       *list[K, V]
+	probation    *list[K, V]
+	protected    *list[K, V]
+	sketch       *sketch
+	seed         maphash.Seed
+	capacity     int
+	windowCap    int
+	protectedCap int
+	onEvict      func(key K, value V, reason cache.EvictReason)
+	stats        cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*TinyLFU[string, int])(nil)
+
+// New 根据构造选项创建 W-TinyLFU 缓存，容量通过 cache.WithCapacity 指定
+func New[K comparable, V any](opts ...cache.Option) *TinyLFU[K, V] {
+	o := cache.NewOptions(opts...)
+
+	t := &TinyLFU[K, V]{
+		items:     make(map[K]*node[K, V]),
+		window:    double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		probation: double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		protected: double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		sketch:    newSketch(o.Capacity),
+		seed:      maphash.MakeSeed(),
+		onEvict:   cache.OnEvict[K, V](o),
+	}
+	t.setCapacity(o.Capacity)
+
+	return t
+}
+
+// NewTinyLFU 创建容量为capacity的 W-TinyLFU 缓存
+func NewTinyLFU[K comparable, V any](capacity int, opts ...cache.Option) *TinyLFU[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// setCapacity 按总容量划分窗口和保护段的大小
+func (t *TinyLFU[K, V]) setCapacity(capacity int) {
+	t.capacity = max(capacity, 0)
+	t.windowCap = min(max(t.capacity/100, 1), t.capacity)
+	t.protectedCap = (t.capacity - t.windowCap) * 80 / 100
+}
+
+func (t *TinyLFU[K, V]) hash(key K) uint64 {
+	return maphash.Comparable(t.seed, key)
+}
+
+// Get 返回key对应的值，并记录这次访问
+func (t *TinyLFU[K, V]) Get(key K) (V, bool) {
+	t.sketch.increment(t.hash(key))
+
+	var zero V
+	n, ok := t.items[key]
+	if !ok {
+		t.stats.Miss()
+		return zero, false
+	}
+
+	t.stats.Hit()
+	t.touch(n)
+	return n.Val.value, true
+}
+
+// Peek 返回key对应的值，不记录访问
+func (t *TinyLFU[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	n, ok := t.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否存在，不记录访问
+func (t *TinyLFU[K, V]) Contains(key K) bool {
+	_, ok := t.items[key]
+	return ok
+}
+
+// Put 写入元素，新元素先进入窗口，被挤出窗口时再决定是否准入主区域
+func (t *TinyLFU[K, V]) Put(key K, value V) error {
+	t.sketch.increment(t.hash(key))
+
+	if n, ok := t.items[key]; ok {
+		old := n.Val.value
+		n.Val.value = value
+		t.touch(n)
+		t.evicted(key, old, cache.EvictReplaced)
+		return nil
+	}
+
+	t.stats.Insert()
+	if t.capacity == 0 {
+		t.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	t.items[key] = t.window.Append(entry[K, V]{key: key, value: value, where: inWindow})
+	for t.window.Size() > t.windowCap {
+		candidate, _ := t.window.RemoveHead()
+		t.admit(candidate)
+	}
+
+	return nil
+}
+
+// admit 决定被挤出窗口的候选者是否进入主区域
+//
+// 候选者仍计算在 Len 中，因此总数不超过容量时主区域还有空位。
+func (t *TinyLFU[K, V]) admit(candidate entry[K, V]) {
+	if t.Len() <= t.capacity {
+		t.insert(t.probation, candidate, inProbation)
+		return
+	}
+
+	victims := t.probation
+	if victims.Size() == 0 {
+		victims = t.protected
+	}
+
+	var victim entry[K, V]
+	found := false
+	for n := range victims.Nodes() {
+		victim, found = n.Val, true
+		break
+	}
+
+	if found && t.sketch.estimate(t.hash(candidate.key)) > t.sketch.estimate(t.hash(victim.key)) {
+		victims.RemoveHead()
+		delete(t.items, victim.key)
+		t.evicted(victim.key, victim.value, cache.EvictCapacity)
+		t.insert(t.probation, candidate, inProbation)
+		return
+	}
+
+	delete(t.items, candidate.key)
+	t.evicted(candidate.key, candidate.value, cache.EvictCapacity)
+}
+
+// touch 处理一次命中：窗口和保护段内移动到尾部，试用段的元素晋升到保护段
+func (t *TinyLFU[K, V]) touch(n *node[K, V]) {
+	switch n.Val.where {
+	case inWindow:
+		t.window.MoveToTail(n)
+
+	case inProtected:
+		t.protected.MoveToTail(n)
+
+	case inProbation:
+		e := n.Val
+		t.probation.Remove(n)
+		t.insert(t.protected, e, inProtected)
+		t.demoteProtected()
+	}
+}
+
+// demoteProtected 保护段超出容量时，把最久未访问的元素降级到试用段
+func (t *TinyLFU[K, V]) demoteProtected() {
+	for t.protected.Size() > t.protectedCap {
+		e, err := t.protected.RemoveHead()
+		if err != nil {
+			return
+		}
+		t.insert(t.probation, e, inProbation)
+	}
+}
+
+func (t *TinyLFU[K, V]) insert(l *list[K, V], e entry[K, V], where int) {
+	e.where = where
+	t.items[e.key] = l.Append(e)
+}
+
+func (t *TinyLFU[K, V]) Remove(key K) bool {
+	n, ok := t.items[key]
+	if !ok {
+		return false
+	}
+
+	e := n.Val
+	t.list(e.where).Remove(n)
+	delete(t.items, key)
+	t.evicted(e.key, e.value, cache.EvictRemoved)
+
+	return true
+}
+
+func (t *TinyLFU[K, V]) Len() int {
+	return len(t.items)
+}
+
+// Stats 返回统计信息，只有 Get 计入命中或未命中，未被准入的候选元素记为一次写入和一次容量淘汰
+func (t *TinyLFU[K, V]) Stats() cache.Stats {
+	return t.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (t *TinyLFU[K, V]) ResetStats() {
+	t.stats.Reset()
+}
+
+// Keys 返回所有键，依次为保护段、试用段和窗口，各自按从最近到最久访问的顺序排列
+func (t *TinyLFU[K, V]) Keys() []K {
+	keys := make([]K, 0, len(t.items))
+	for key := range t.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// All 返回遍历所有元素的迭代器，顺序与 Keys 相同，遍历不记录访问
+func (t *TinyLFU[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, l := range []*list[K, V]{t.protected, t.probation, t.window} {
+			for n := range l.Backward() {
+				if !yield(n.Val.key, n.Val.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clear 删除所有元素，频率估计保持不变
+func (t *TinyLFU[K, V]) Clear() {
+	lists := []*list[K, V]{t.window, t.probation, t.protected}
+	t.items = make(map[K]*node[K, V])
+	t.window = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	t.probation = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	t.protected = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+
+	for _, l := range lists {
+		for n := range l.Nodes() {
+			t.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+		}
+	}
+}
+
+// Resize 修改容量并重新划分各区域，容量缩小时依次从试用段、保护段和窗口
+// 淘汰最久未访问的元素，返回淘汰的数量
+//
+// 频率估计器按新容量重新分配，缓存中现有元素的访问频率被保留，
+// 已经不在缓存中的键的历史频率被丢弃。
+func (t *TinyLFU[K, V]) Resize(capacity int) int {
+	t.setCapacity(capacity)
+
+	evicted := 0
+	for t.Len() > t.capacity {
+		for _, l := range []*list[K, V]{t.probation, t.protected, t.window} {
+			if e, err := l.RemoveHead(); err == nil {
+				delete(t.items, e.key)
+				t.evicted(e.key, e.value, cache.EvictCapacity)
+				evicted++
+				break
+			}
+		}
+	}
+
+	// 窗口多出的元素直接移入主区域，总数已不超过容量
+	for t.window.Size() > t.windowCap {
+		e, _ := t.window.RemoveHead()
+		t.insert(t.probation, e, inProbation)
+	}
+	t.demoteProtected()
+
+	hashes := make([]uint64, 0, len(t.items))
+	for key := range t.items {
+		hashes = append(hashes, t.hash(key))
+	}
+	t.sketch = t.sketch.resize(t.capacity, hashes)
+
+	return evicted
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+//
+// 被挤出窗口后未被准入的候选元素同样以 cache.EvictCapacity 触发回调。
+func (t *TinyLFU[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	t.onEvict = fn
+}
+
+func (t *TinyLFU[K, V]) list(where int) *list[K, V] {
+	switch where {
+	case inWindow:
+		return t.window
+	case inProbation:
+		return t.probation
+	default:
+		return t.protected
+	}
+}
+
+func (t *TinyLFU[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	t.stats.Evict(reason)
+	if t.onEvict != nil {
+		t.onEvict(key, value, reason)
+	}
+}
@@ -0,0 +1,226 @@
+package tinylfu
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TinyLFUTestSuite 是 W-TinyLFU 缓存的测试套件
+type TinyLFUTestSuite struct {
+	suite.Suite
+	cache *TinyLFU[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *TinyLFUTestSuite) SetupTest() {
+	s.cache = NewTinyLFU[string, int](100)
+}
+
+// TestRegions 测试各区域的容量划分
+func (s *TinyLFUTestSuite) TestRegions() {
+	s.Equal(1, s.cache.windowCap)
+	s.Equal(79, s.cache.protectedCap)
+
+	c := NewTinyLFU[string, int](1000)
+	s.Equal(10, c.windowCap)
+	s.Equal(792, c.protectedCap)
+
+	c = NewTinyLFU[string, int](1)
+	s.Equal(1, c.windowCap)
+	s.Equal(0, c.protectedCap)
+}
+
+// TestWindowToProbation 测试元素被挤出窗口后在有空位时直接进入试用段
+func (s *TinyLFUTestSuite) TestWindowToProbation() {
+	s.NoError(s.cache.Put("key1", 1))
+	s.Equal(inWindow, s.cache.items["key1"].Val.where)
+
+	s.NoError(s.cache.Put("key2", 2))
+	s.Equal(inProbation, s.cache.items["key1"].Val.where)
+	s.Equal(inWindow, s.cache.items["key2"].Val.where)
+
+	// 试用段中的元素被命中后晋升到保护段
+	s.cache.Get("key1")
+	s.Equal(inProtected, s.cache.items["key1"].Val.where)
+}
+
+// TestAdmission 测试大量只出现一次的键不会挤掉仍在被访问的热点数据
+func (s *TinyLFUTestSuite) TestAdmission() {
+	for i := range 20 {
+		key := "hot" + strconv.Itoa(i)
+		s.NoError(s.cache.Put(key, i))
+		for range 5 {
+			s.cache.Get(key)
+		}
+	}
+
+	for i := range 500 {
+		s.NoError(s.cache.Put("once"+strconv.Itoa(i), i))
+		// 主区域写满之前每个热点键都至少被访问一次，从试用段晋升到保护段
+		if i%4 == 0 {
+			s.cache.Get("hot" + strconv.Itoa(i/4%20))
+		}
+	}
+
+	for i := range 20 {
+		s.True(s.cache.Contains("hot" + strconv.Itoa(i)))
+	}
+	s.Equal(100, s.cache.Len())
+}
+
+// TestAdmitFrequentCandidate 测试频率更高的候选者可以替换主区域的元素
+func (s *TinyLFUTestSuite) TestAdmitFrequentCandidate() {
+	c := NewTinyLFU[string, int](3)
+	s.NoError(c.Put("key1", 1))
+	s.NoError(c.Put("key2", 2))
+	s.NoError(c.Put("key3", 3))
+
+	// key4在写入前已被多次访问（未命中也计入频率）
+	for range 5 {
+		c.Get("key4")
+	}
+	s.NoError(c.Put("key4", 4))
+	s.NoError(c.Put("key5", 5))
+
+	s.True(c.Contains("key4"))
+	s.Equal(3, c.Len())
+}
+
+// TestOnEvict 测试通过 OnEvict 设置和取消回调
+func (s *TinyLFUTestSuite) TestOnEvict() {
+	got := make(map[string]cache.EvictReason)
+	s.cache.OnEvict(func(key string, _ int, reason cache.EvictReason) {
+		got[key] = reason
+	})
+
+	for i := range 101 {
+		s.NoError(s.cache.Put(strconv.Itoa(i), i))
+	}
+	s.True(s.cache.Remove("100"))
+	s.Len(got, 2)
+	s.Equal(cache.EvictRemoved, got["100"])
+
+	s.cache.OnEvict(nil)
+	s.cache.Clear()
+	s.Len(got, 2)
+}
+
+// TestAll 测试All依次遍历保护段、试用段和窗口，遍历期间可以删除当前元素
+func (s *TinyLFUTestSuite) TestAll() {
+	c := NewTinyLFU[string, int](10)
+	for i := range 3 {
+		s.NoError(c.Put(strconv.Itoa(i), i))
+	}
+	c.Get("0")
+
+	var keys []string
+	for key, value := range c.All() {
+		keys = append(keys, key)
+		if value == 1 {
+			s.True(c.Remove(key))
+		}
+	}
+	s.Equal([]string{"0", "1", "2"}, keys)
+	s.Equal([]string{"0", "2"}, c.Keys())
+}
+
+// TestResizeSketch 测试扩容后频率估计器随之扩大，现有热点数据的频率被保留
+func (s *TinyLFUTestSuite) TestResizeSketch() {
+	for i := range 20 {
+		key := "hot" + strconv.Itoa(i)
+		s.NoError(s.cache.Put(key, i))
+		for range 5 {
+			s.cache.Get(key)
+		}
+	}
+	before := s.cache.sketch.estimate(s.cache.hash("hot0"))
+
+	s.Zero(s.cache.Resize(10000))
+	s.Len(s.cache.sketch.rows[0], 16384)
+	s.Equal(before, s.cache.sketch.estimate(s.cache.hash("hot0")))
+
+	// 扩容后大量只出现一次的键仍然不能挤掉热点数据
+	for i := range 30000 {
+		s.NoError(s.cache.Put("once"+strconv.Itoa(i), i))
+		if i%100 == 0 {
+			s.cache.Get("hot" + strconv.Itoa(i/100%20))
+		}
+	}
+	for i := range 20 {
+		s.True(s.cache.Contains("hot" + strconv.Itoa(i)))
+	}
+	s.Equal(10000, s.cache.Len())
+}
+
+// TestZipfHitRatio 测试在Zipf分布的访问序列上命中率高于LRU
+func (s *TinyLFUTestSuite) TestZipfHitRatio() {
+	for _, skew := range []float64{1.01, 1.2} {
+		trace := cachetest.ZipfTrace(42, skew, 100000, 200000)
+		tiny := cachetest.HitRatio(NewTinyLFU[int, int](1000), trace)
+		plain := cachetest.HitRatio(lru.NewLRU[int, int](1000), trace)
+
+		s.T().Logf("zipf s=%.2f: tinylfu=%.4f lru=%.4f", skew, tiny, plain)
+		s.Greater(tiny, plain)
+	}
+}
+
+// TestStats 测试命中、未命中、写入、更新和淘汰的统计
+func (s *TinyLFUTestSuite) TestStats() {
+	s.cache.Put("key1", 1)
+	s.cache.Put("key1", 10)
+	s.cache.Put("key2", 2)
+	s.cache.Put("key3", 3)
+	s.cache.Get("key1")
+	s.cache.Get("key3")
+	s.cache.Get("key9")
+	s.cache.Peek("key2")
+	s.cache.Remove("key3")
+
+	st := s.cache.Stats()
+	s.Equal(uint64(2), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(3), st.Insertions)
+	s.Equal(uint64(1), st.Updates)
+	s.Equal(uint64(1), st.Evictions[cache.EvictRemoved])
+	s.Equal(2, st.Size)
+	s.InDelta(2.0/3, st.HitRatio(), 1e-9)
+
+	// 未被准入主区域的候选者计为一次容量淘汰
+	for i := range 200 {
+		s.cache.Put("once"+strconv.Itoa(i), i)
+	}
+	st = s.cache.Stats()
+	s.Equal(s.cache.Len(), st.Size)
+	s.Equal(uint64(102), st.Evictions[cache.EvictCapacity])
+
+	s.cache.ResetStats()
+	st = s.cache.Stats()
+	s.Zero(st.Hits)
+	s.Zero(st.Evicted())
+	s.Equal(100, st.Size)
+
+	// 容量为0时写入后立即被淘汰
+	c := NewTinyLFU[string, int](0)
+	c.Put("key1", 1)
+	st = c.Stats()
+	s.Equal(uint64(1), st.Insertions)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(0, st.Size)
+}
+
+// TestTinyLFU 运行所有 W-TinyLFU 测试
+func TestTinyLFU(t *testing.T) {
+	suite.Run(t, new(TinyLFUTestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}
@@ -0,0 +1,245 @@
+// Package tokenbucket 实现了令牌桶限流器
+//
+// 桶中最多存放burst个令牌，以固定速率补充；每个请求消耗一个令牌，桶中有足够的
+// 令牌时请求立即通过，因此空闲一段时间后可以容忍一次突发。令牌数使用“令牌×1000”
+// 的定点数表示，补充在每次调用时按经过的时间惰性计算。
+//
+// 桶的状态（令牌数、更新时间、速率和容量）保存在一个不可变的快照中，通过原子
+// 指针和CAS循环整体替换，无需加锁；SetRate 和 SetBurst 也通过同样的方式在运行时
+// 修改参数。
+package tokenbucket
+
+import (
+	"algorithm/clock"
+	"algorithm/ratelimit"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// unit 是一个令牌在定点数中对应的数量
+const unit = 1000
+
+// state 是桶在某一时刻的状态，创建后不再修改
+type state struct {
+	last   int64   // 上次补充令牌的时间（Unix纳秒）
+	tokens int64   // 当前令牌数（×1000），有未到期的预约时可能为负数
+	limit  float64 // 每秒补充的令牌数
+	rate   float64 // 每纳秒补充的令牌数（×1000）
+	burst  int64
+}
+
+// TokenBucket 令牌桶限流器，实现了 ratelimit.Limiter
+type TokenBucket struct {
+	clock clock.Clock
+	state atomic.Pointer[state]
+}
+
+// Option 是令牌桶的构造选项
+type Option func(t *TokenBucket)
+
+// WithClock 指定令牌桶使用的时钟，默认使用 clock.Real
+func WithClock(c clock.Clock) Option {
+	return func(t *TokenBucket) {
+		t.clock = c
+	}
+}
+
+// New 创建最多存放burst个令牌、每秒补充rate个令牌的令牌桶，新建的桶是满的
+func New(burst int64, rate float64, opts ...Option) *TokenBucket {
+	t := &TokenBucket{clock: clock.Real}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	t.state.Store(&state{
+		last:   t.clock.Now().UnixNano(),
+		tokens: burst * unit,
+		limit:  rate,
+		rate:   perNano(rate),
+		burst:  burst,
+	})
+
+	return t
+}
+
+var _ ratelimit.Limiter = (*TokenBucket)(nil)
+
+// Limit 返回每秒补充的令牌数
+func (t *TokenBucket) Limit() float64 {
+	return t.state.Load().limit
+}
+
+// Burst 返回桶的容量，即一次最多可以通过的请求数
+func (t *TokenBucket) Burst() int {
+	return int(t.state.Load().burst)
+}
+
+// SetRate 修改每秒补充的令牌数，修改之前经过的时间仍按原速率补充
+func (t *TokenBucket) SetRate(rate float64) {
+	t.update(t.clock.Now(), func(s *state) {
+		s.limit = rate
+		s.rate = perNano(rate)
+	})
+}
+
+// SetBurst 修改桶的容量，容量缩小时多出的令牌被丢弃，扩大时不会补充额外的令牌
+func (t *TokenBucket) SetBurst(burst int) {
+	t.update(t.clock.Now(), func(s *state) {
+		s.burst = int64(burst)
+		s.tokens = min(s.tokens, s.burst*unit)
+	})
+}
+
+// Allow 判断当前是否可以通过一个请求
+func (t *TokenBucket) Allow() bool {
+	return t.AllowN(1)
+}
+
+// AllowN 判断当前是否可以通过n个请求，可以时消耗n个令牌
+func (t *TokenBucket) AllowN(n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	ts := t.clock.Now().UnixNano()
+	for {
+		old := t.state.Load()
+		cur := old.refill(ts)
+		if cur.tokens < int64(n)*unit {
+			return false
+		}
+
+		cur.tokens -= int64(n) * unit
+		if t.state.CompareAndSwap(old, cur) {
+			return true
+		}
+	}
+}
+
+// Reserve 预约一个请求，等价于 ReserveN(1)
+func (t *TokenBucket) Reserve() ratelimit.Reservation {
+	return t.ReserveN(1)
+}
+
+// ReserveN 预约n个请求，返回的预约说明需要等待多久才能执行
+//
+// 预约总会立即消耗令牌，令牌数可能暂时为负，后续的请求需要等待更久。
+// n超过容量时预约无效（OK 返回false），桶的状态不变。
+func (t *TokenBucket) ReserveN(n int) ratelimit.Reservation {
+	now := t.clock.Now()
+	if n <= 0 {
+		return ratelimit.NewReservation(t.clock, 0, now, nil)
+	}
+
+	ts := now.UnixNano()
+	for {
+		old := t.state.Load()
+		if int64(n) > old.burst {
+			return ratelimit.InvalidReservation()
+		}
+
+		cur := old.refill(ts)
+		cur.tokens -= int64(n) * unit
+		if t.state.CompareAndSwap(old, cur) {
+			return ratelimit.NewReservation(t.clock, n, now.Add(cur.delay()), t.refund)
+		}
+	}
+}
+
+// Wait 阻塞直到可以通过一个请求，等价于 WaitN(ctx, 1)
+func (t *TokenBucket) Wait(ctx context.Context) error {
+	return t.WaitN(ctx, 1)
+}
+
+// WaitN 阻塞直到可以通过n个请求，或者ctx被取消
+//
+// n超过容量时返回 ratelimit.ErrExceedsBurst；ctx的截止时间早于需要等待的时间时
+// 立即返回 ratelimit.ErrDeadline。返回错误时预约被取消，消耗的令牌退回桶中。
+func (t *TokenBucket) WaitN(ctx context.Context, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := t.ReserveN(n)
+	if !r.OK() {
+		return fmt.Errorf("%w: n=%d, burst=%d", ratelimit.ErrExceedsBurst, n, t.Burst())
+	}
+
+	return ratelimit.Wait(ctx, t.clock, r)
+}
+
+// update 先把令牌补充到now时刻，再用fn修改状态
+func (t *TokenBucket) update(now time.Time, fn func(s *state)) {
+	ts := now.UnixNano()
+	for {
+		old := t.state.Load()
+		cur := old.refill(ts)
+		fn(cur)
+		if t.state.CompareAndSwap(old, cur) {
+			return
+		}
+	}
+}
+
+// refund 在now时刻退回n个令牌，令牌数最多为容量
+func (t *TokenBucket) refund(now time.Time, n int) {
+	t.update(now, func(s *state) {
+		s.tokens = min(s.tokens+int64(n)*unit, s.burst*unit)
+	})
+}
+
+// refill 返回补充令牌到ts时刻后的新状态
+//
+// 补充的令牌向下取整，更新时间只前进补充这些令牌所用的时间，不足一个单位的
+// 部分留到下次累计。桶满或速率为0期间的时间不再累计。并发调用时ts可能早于上次更新的
+// 时间，此时不补充。
+func (s *state) refill(ts int64) *state {
+	cur := *s
+	if ts <= s.last {
+		return &cur
+	}
+
+	capacity := s.burst * unit
+	if s.tokens >= capacity || s.rate <= 0 {
+		cur.last = ts
+		return &cur
+	}
+
+	added := float64(ts-s.last) * s.rate
+	if added >= float64(capacity-s.tokens) {
+		cur.tokens = capacity
+		cur.last = ts
+		return &cur
+	}
+
+	units := int64(added)
+	if units == 0 {
+		return &cur
+	}
+
+	cur.tokens += units
+	cur.last = s.last + int64(float64(units)/s.rate)
+	return &cur
+}
+
+// delay 返回令牌数从负数恢复到0所需的时间
+func (s *state) delay() time.Duration {
+	if s.tokens >= 0 {
+		return 0
+	}
+
+	d := float64(-s.tokens) / s.rate
+	if s.rate <= 0 || d >= float64(ratelimit.InfDuration) {
+		return ratelimit.InfDuration
+	}
+
+	// 向上取整，保证等待结束时令牌确实已经足够
+	return time.Duration(d) + 1
+}
+
+// perNano 把每秒补充的令牌数换算为每纳秒补充的定点数
+func perNano(rate float64) float64 {
+	return rate * unit / float64(time.Second)
+}
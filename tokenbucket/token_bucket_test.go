@@ -0,0 +1,145 @@
+package tokenbucket
+
+import (
+	"algorithm/clock"
+	"algorithm/leakybucket"
+	"algorithm/ratelimit"
+	"algorithm/ratelimit/ratelimittest"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TokenBucketTestSuite 是令牌桶的测试套件
+type TokenBucketTestSuite struct {
+	suite.Suite
+	clock  *clock.Manual
+	bucket *TokenBucket
+}
+
+// SetupTest 在每个测试用例之前执行，创建容量为10、每秒补充10个令牌的令牌桶
+func (s *TokenBucketTestSuite) SetupTest() {
+	s.clock = clock.NewManual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s.bucket = New(10, 10, WithClock(s.clock))
+}
+
+// TestRefill 测试令牌按经过的时间惰性补充，不超过容量
+func (s *TokenBucketTestSuite) TestRefill() {
+	s.True(s.bucket.AllowN(10))
+	s.False(s.bucket.Allow())
+
+	s.clock.Advance(350 * time.Millisecond)
+	s.True(s.bucket.AllowN(3))
+	s.False(s.bucket.Allow())
+
+	// 不足一个令牌的50毫秒留到下次累计
+	s.clock.Advance(50 * time.Millisecond)
+	s.True(s.bucket.Allow())
+
+	s.clock.Advance(24 * time.Hour)
+	s.True(s.bucket.AllowN(10))
+	s.False(s.bucket.Allow())
+}
+
+// TestSetRate 测试运行时修改速率，修改前经过的时间按原速率补充
+func (s *TokenBucketTestSuite) TestSetRate() {
+	s.bucket.AllowN(10)
+
+	s.clock.Advance(500 * time.Millisecond)
+	s.bucket.SetRate(100)
+	s.Equal(100.0, s.bucket.Limit())
+
+	// 原速率补充了5个令牌，新速率下100毫秒再补充10个，但不超过容量
+	s.True(s.bucket.AllowN(5))
+	s.False(s.bucket.Allow())
+	s.clock.Advance(50 * time.Millisecond)
+	s.True(s.bucket.AllowN(5))
+	s.False(s.bucket.Allow())
+
+	s.bucket.SetRate(0)
+	s.clock.Advance(time.Hour)
+	s.False(s.bucket.Allow())
+	r := s.bucket.Reserve()
+	s.Equal(ratelimit.InfDuration, r.Delay())
+	r.Cancel()
+
+	// 速率为0期间不累计令牌
+	s.bucket.SetRate(10)
+	s.False(s.bucket.Allow())
+	s.clock.Advance(100 * time.Millisecond)
+	s.True(s.bucket.Allow())
+}
+
+// TestSetBurst 测试运行时修改容量
+func (s *TokenBucketTestSuite) TestSetBurst() {
+	// 缩小容量时丢弃多出的令牌
+	s.bucket.SetBurst(3)
+	s.Equal(3, s.bucket.Burst())
+	s.False(s.bucket.AllowN(4))
+	s.True(s.bucket.AllowN(3))
+
+	// 扩大容量不会立即补充令牌，之后按速率补满
+	s.bucket.SetBurst(20)
+	s.False(s.bucket.Allow())
+	s.clock.Advance(2 * time.Second)
+	s.True(s.bucket.AllowN(20))
+	s.False(s.bucket.Allow())
+}
+
+// TestBurstTolerance 测试同样的长期速率下，令牌桶容忍突发而严格计量的漏桶平滑请求
+func (s *TokenBucketTestSuite) TestBurstTolerance() {
+	leaky := leakybucket.New(1, 10, leakybucket.WithClock(s.clock))
+
+	// 空闲之后同时到达的10个请求：令牌桶全部通过，漏桶只通过1个
+	tokens, drops := 0, 0
+	for range 10 {
+		if s.bucket.Allow() {
+			tokens++
+		}
+		if leaky.Allow() {
+			drops++
+		}
+	}
+	s.Equal(10, tokens)
+	s.Equal(1, drops)
+
+	// 持续的请求流中两者的通过速率相同
+	tokens, drops = 0, 0
+	for range 1000 {
+		s.clock.Advance(10 * time.Millisecond)
+		if s.bucket.Allow() {
+			tokens++
+		}
+		if leaky.Allow() {
+			drops++
+		}
+	}
+	s.Equal(100, tokens)
+	s.Equal(100, drops)
+}
+
+// TestEquivalentToLeakyBucket 测试容量相同时，令牌桶与按水位计量的漏桶对任意请求序列的判断一致
+func (s *TokenBucketTestSuite) TestEquivalentToLeakyBucket() {
+	leaky := leakybucket.New(10, 10, leakybucket.WithClock(s.clock))
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for i := range 2000 {
+		s.clock.Advance(time.Duration(r.IntN(50)) * time.Millisecond)
+		n := r.IntN(4)
+		s.Equal(leaky.AllowN(n), s.bucket.AllowN(n), "request %d", i)
+	}
+}
+
+// TestTokenBucket 运行所有令牌桶测试
+func TestTokenBucket(t *testing.T) {
+	suite.Run(t, new(TokenBucketTestSuite))
+}
+
+// TestConformance 运行限流器接口的一致性测试
+func TestConformance(t *testing.T) {
+	ratelimittest.Run(t, func(limit float64, burst int, clk clock.Clock) ratelimit.Limiter {
+		return New(int64(burst), limit, WithClock(clk))
+	})
+}
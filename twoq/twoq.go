@@ -0,0 +1,303 @@
+// Package twoq 实现了2Q缓存
+//
+// 2Q把缓存分为两个常驻队列：A1in是保存新元素的FIFO队列，Am是保存热点元素的LRU队列；
+// 另有一个只保存键的幽灵队列A1out，记录最近从A1in中淘汰的键。新元素先进入A1in，
+// 在A1in中再次被访问不会改变它的位置；只有在被淘汰到A1out之后再次写入，
+// 才说明它在较长的时间内被重复访问，从而进入Am。只访问一次的扫描流量只会
+// 流经A1in，不会冲刷Am中的热点数据。
+//
+// 所有队列的头部是最早进入（或最久未访问）的一端，尾部是最近的一端。
+package twoq
+
+import (
+	"algorithm/cache"
+	"algorithm/double_linked_list"
+	"iter"
+)
+
+// 元素所在的队列
+const (
+	inA1in = iota
+	inAm
+	inA1out
+)
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	where int
+}
+
+type node[K comparable, V any] = double_linked_list.DNode[entry[K, V]]
+
+type list[K comparable, V any] = double_linked_list.DoubleLinkedList[entry[K, V]]
+
+type TwoQ[K comparable, V any] struct {
+	items    map[K]*node[K, V]
+	a1in     *list[K, V]
+	am       *list[K, V]
+	a1out    *list[K, V]
+	capacity int
+	kin      int // A1in的目标大小
+	kout     int // A1out的最大长度
+	onEvict  func(key K, value V, reason cache.EvictReason)
+	stats    cache.StatsCounter
+}
+
+var _ cache.Cache[string, int] = (*TwoQ[string, int])(nil)
+
+// New 根据构造选项创建2Q缓存，容量通过 cache.WithCapacity 指定
+func New[K comparable, V any](opts ...cache.Option) *TwoQ[K, V] {
+	o := cache.NewOptions(opts...)
+
+	q := &TwoQ[K, V]{
+		items:   make(map[K]*node[K, V]),
+		a1in:    double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		am:      double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		a1out:   double_linked_list.NewDoubleLinkedList[entry[K, V]](),
+		onEvict: cache.OnEvict[K, V](o),
+	}
+	q.setCapacity(o.Capacity)
+
+	return q
+}
+
+// NewTwoQ 创建容量为capacity的2Q缓存
+func NewTwoQ[K comparable, V any](capacity int, opts ...cache.Option) *TwoQ[K, V] {
+	return New[K, V](append([]cache.Option{cache.WithCapacity(capacity)}, opts...)...)
+}
+
+// setCapacity 按论文推荐的比例设置A1in占容量的25%，A1out记录容量50%的键
+func (q *TwoQ[K, V]) setCapacity(capacity int) {
+	q.capacity = max(capacity, 0)
+	q.kin = max(q.capacity/4, 1)
+	q.kout = max(q.capacity/2, 1)
+}
+
+// Get 返回key对应的值，Am中的元素被移动到尾部，A1in中的元素位置不变
+func (q *TwoQ[K, V]) Get(key K) (V, bool) {
+	var zero V
+	n, ok := q.resident(key)
+	if !ok {
+		q.stats.Miss()
+		return zero, false
+	}
+
+	q.stats.Hit()
+	if n.Val.where == inAm {
+		q.am.MoveToTail(n)
+	}
+
+	return n.Val.value, true
+}
+
+// Peek 返回key对应的值，不影响淘汰顺序
+func (q *TwoQ[K, V]) Peek(key K) (V, bool) {
+	var zero V
+	n, ok := q.resident(key)
+	if !ok {
+		return zero, false
+	}
+
+	return n.Val.value, true
+}
+
+// Contains 判断key是否在缓存中，A1out中的键不算在内
+func (q *TwoQ[K, V]) Contains(key K) bool {
+	_, ok := q.resident(key)
+	return ok
+}
+
+// Put 写入元素，A1out中的键直接进入Am，其他新键进入A1in
+func (q *TwoQ[K, V]) Put(key K, value V) error {
+	n, ok := q.items[key]
+	if ok && n.Val.where != inA1out {
+		old := n.Val.value
+		n.Val.value = value
+		if n.Val.where == inAm {
+			q.am.MoveToTail(n)
+		}
+		q.evicted(key, old, cache.EvictReplaced)
+		return nil
+	}
+
+	// 先删除幽灵键，避免腾出空间时它作为A1out中最早的键被丢弃
+	if ok {
+		q.a1out.Remove(n)
+		delete(q.items, key)
+	}
+
+	q.stats.Insert()
+	if q.capacity == 0 {
+		q.evicted(key, value, cache.EvictCapacity)
+		return nil
+	}
+
+	if q.Len() >= q.capacity {
+		q.reclaim()
+	}
+
+	if ok {
+		// A1out命中，说明该键在较长的时间内被重复访问
+		q.items[key] = q.am.Append(entry[K, V]{key: key, value: value, where: inAm})
+		return nil
+	}
+
+	q.items[key] = q.a1in.Append(entry[K, V]{key: key, value: value, where: inA1in})
+	return nil
+}
+
+// reclaim 淘汰一个元素：A1in超过目标大小时淘汰其头部并记入A1out，否则淘汰Am的头部
+func (q *TwoQ[K, V]) reclaim() bool {
+	if q.a1in.Size() > q.kin || (q.am.Size() == 0 && q.a1in.Size() > 0) {
+		e, _ := q.a1in.RemoveHead()
+		q.remember(e.key)
+		q.evicted(e.key, e.value, cache.EvictCapacity)
+		return true
+	}
+
+	e, err := q.am.RemoveHead()
+	if err != nil {
+		return false
+	}
+
+	delete(q.items, e.key)
+	q.evicted(e.key, e.value, cache.EvictCapacity)
+	return true
+}
+
+// remember 把键记入A1out，超出长度时丢弃最早的键
+func (q *TwoQ[K, V]) remember(key K) {
+	q.items[key] = q.a1out.Append(entry[K, V]{key: key, where: inA1out})
+	q.trimGhosts()
+}
+
+func (q *TwoQ[K, V]) trimGhosts() {
+	for q.a1out.Size() > q.kout {
+		e, _ := q.a1out.RemoveHead()
+		delete(q.items, e.key)
+	}
+}
+
+// Remove 删除缓存中的元素；A1out中的键也会被清除，但返回false
+func (q *TwoQ[K, V]) Remove(key K) bool {
+	n, ok := q.items[key]
+	if !ok {
+		return false
+	}
+
+	e := n.Val
+	q.list(e.where).Remove(n)
+	delete(q.items, key)
+	if e.where == inA1out {
+		return false
+	}
+
+	q.evicted(e.key, e.value, cache.EvictRemoved)
+	return true
+}
+
+// Len 返回缓存中元素的数量，不包括A1out
+func (q *TwoQ[K, V]) Len() int {
+	return q.a1in.Size() + q.am.Size()
+}
+
+// Size 与 Len 相同
+func (q *TwoQ[K, V]) Size() int {
+	return q.Len()
+}
+
+// Keys 返回缓存中的所有键，顺序与 All 相同
+func (q *TwoQ[K, V]) Keys() []K {
+	keys := make([]K, 0, q.Len())
+	for key := range q.All() {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// All 返回遍历缓存中所有元素的迭代器，先Am后A1in，各自从最近到最久排列，遍历不影响淘汰顺序
+func (q *TwoQ[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, l := range []*list[K, V]{q.am, q.a1in} {
+			for n := range l.Backward() {
+				if !yield(n.Val.key, n.Val.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Clear 删除所有元素和A1out中的键，每个元素都会以 cache.EvictRemoved 触发回调
+func (q *TwoQ[K, V]) Clear() {
+	a1in, am := q.a1in, q.am
+	q.items = make(map[K]*node[K, V])
+	q.a1in = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	q.am = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+	q.a1out = double_linked_list.NewDoubleLinkedList[entry[K, V]]()
+
+	for _, l := range []*list[K, V]{a1in, am} {
+		for n := range l.Nodes() {
+			q.evicted(n.Val.key, n.Val.value, cache.EvictRemoved)
+		}
+	}
+}
+
+// Resize 修改容量并重新计算各队列的大小，容量缩小时按2Q的规则淘汰多出的元素，
+// 返回淘汰的数量
+func (q *TwoQ[K, V]) Resize(capacity int) int {
+	q.setCapacity(capacity)
+
+	evicted := 0
+	for q.Len() > q.capacity && q.reclaim() {
+		evicted++
+	}
+	q.trimGhosts()
+
+	return evicted
+}
+
+// OnEvict 设置元素离开缓存时的回调，reason说明离开的原因；传入nil取消回调
+func (q *TwoQ[K, V]) OnEvict(fn func(key K, value V, reason cache.EvictReason)) {
+	q.onEvict = fn
+}
+
+// Stats 返回统计信息，Peek 和 Contains 不计入命中或未命中
+func (q *TwoQ[K, V]) Stats() cache.Stats {
+	return q.stats.Snapshot()
+}
+
+// ResetStats 将统计计数清零，当前元素数量保持不变
+func (q *TwoQ[K, V]) ResetStats() {
+	q.stats.Reset()
+}
+
+// resident 返回在A1in或Am中的节点
+func (q *TwoQ[K, V]) resident(key K) (*node[K, V], bool) {
+	n, ok := q.items[key]
+	if !ok || n.Val.where == inA1out {
+		return nil, false
+	}
+
+	return n, true
+}
+
+func (q *TwoQ[K, V]) list(where int) *list[K, V] {
+	switch where {
+	case inA1in:
+		return q.a1in
+	case inAm:
+		return q.am
+	default:
+		return q.a1out
+	}
+}
+
+func (q *TwoQ[K, V]) evicted(key K, value V, reason cache.EvictReason) {
+	q.stats.Evict(reason)
+	if q.onEvict != nil {
+		q.onEvict(key, value, reason)
+	}
+}
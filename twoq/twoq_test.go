@@ -0,0 +1,178 @@
+package twoq
+
+import (
+	"algorithm/cache"
+	"algorithm/cache/cachetest"
+	"algorithm/lru"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// TwoQTestSuite 是2Q缓存的测试套件
+type TwoQTestSuite struct {
+	suite.Suite
+	q *TwoQ[string, int]
+}
+
+// SetupTest 在每个测试用例之前执行，初始化测试环境
+func (s *TwoQTestSuite) SetupTest() {
+	s.q = NewTwoQ[string, int](4)
+}
+
+// TestSizes 测试各队列的大小
+func (s *TwoQTestSuite) TestSizes() {
+	s.Equal(1, s.q.kin)
+	s.Equal(2, s.q.kout)
+
+	q := NewTwoQ[string, int](100)
+	s.Equal(25, q.kin)
+	s.Equal(50, q.kout)
+}
+
+// TestA1inIsFIFO 测试A1in中的元素被再次访问时位置不变
+func (s *TwoQTestSuite) TestA1inIsFIFO() {
+	for i := range 4 {
+		s.NoError(s.q.Put(strconv.Itoa(i), i))
+	}
+	s.q.Get("0")
+
+	// 0仍然最先被淘汰，并被记入A1out
+	s.NoError(s.q.Put("4", 4))
+	s.False(s.q.Contains("0"))
+	s.Equal(inA1out, s.q.items["0"].Val.where)
+	s.Equal(4, s.q.Len())
+}
+
+// TestGhostHitToAm 测试A1out中的键再次写入时进入Am
+func (s *TwoQTestSuite) TestGhostHitToAm() {
+	for i := range 5 {
+		s.NoError(s.q.Put(strconv.Itoa(i), i))
+	}
+	s.Equal(inA1out, s.q.items["0"].Val.where)
+
+	_, ok := s.q.Get("0")
+	s.False(ok)
+	s.NoError(s.q.Put("0", 10))
+	s.Equal(inAm, s.q.items["0"].Val.where)
+	s.Equal(4, s.q.Len())
+	val, ok := s.q.Peek("0")
+	s.True(ok)
+	s.Equal(10, val)
+}
+
+// TestGhostBounded 测试A1out的长度有上限
+func (s *TwoQTestSuite) TestGhostBounded() {
+	for i := range 100 {
+		s.NoError(s.q.Put(strconv.Itoa(i), i))
+	}
+
+	s.Equal(4, s.q.Len())
+	s.Equal(2, s.q.a1out.Size())
+	s.Len(s.q.items, 6)
+}
+
+// TestAmEviction 测试A1in不超过目标大小时淘汰Am中最久未访问的元素
+func (s *TwoQTestSuite) TestAmEviction() {
+	// 让key0、key1依次经过A1out进入Am
+	for _, key := range []string{"key0", "key1"} {
+		s.NoError(s.q.Put(key, 0))
+		for i := range 4 {
+			s.NoError(s.q.Put(key+"-filler"+strconv.Itoa(i), i))
+		}
+		s.NoError(s.q.Put(key, 0))
+		s.Equal(inAm, s.q.items[key].Val.where)
+	}
+	s.Equal(2, s.q.a1in.Size())
+	s.q.Get("key0")
+
+	// 先把A1in压缩到目标大小，再淘汰Am中最久未访问的key1
+	s.Equal(2, s.q.Resize(2))
+	s.Equal([]string{"key0", "key1-filler3"}, s.q.Keys())
+}
+
+// TestRemoveGhost 测试删除A1out中的键返回false
+func (s *TwoQTestSuite) TestRemoveGhost() {
+	for i := range 5 {
+		s.NoError(s.q.Put(strconv.Itoa(i), i))
+	}
+
+	s.False(s.q.Remove("0"))
+	_, ok := s.q.items["0"]
+	s.False(ok)
+	s.True(s.q.Remove("1"))
+	s.Equal(3, s.q.Len())
+	s.Equal(3, s.q.Size())
+}
+
+// TestScanResistance 测试周期性的扫描不会冲刷热点数据，而LRU会
+func (s *TwoQTestSuite) TestScanResistance() {
+	const capacity = 100
+	q := NewTwoQ[string, int](capacity)
+	l := lru.NewLRU[string, int](capacity)
+
+	qHits, lruHits, total := 0, 0, 0
+	access := func(key string, hot bool) {
+		if _, ok := q.Get(key); ok && hot {
+			qHits++
+		} else if !ok {
+			q.Put(key, 0)
+		}
+		if _, ok := l.Get(key); ok && hot {
+			lruHits++
+		} else if !ok {
+			l.Put(key, 0)
+		}
+		if hot {
+			total++
+		}
+	}
+
+	// 每一轮访问热点数据两次，再扫描一遍冷数据
+	scan := 0
+	for range 10 {
+		for range 2 {
+			for i := range 50 {
+				access("hot"+strconv.Itoa(i), true)
+			}
+		}
+		for range capacity {
+			access("scan"+strconv.Itoa(scan), false)
+			scan++
+		}
+	}
+
+	qRatio := float64(qHits) / float64(total)
+	lruRatio := float64(lruHits) / float64(total)
+	s.T().Logf("hot hit ratio: 2q=%.2f lru=%.2f", qRatio, lruRatio)
+	s.Greater(qRatio, 0.85)
+	s.InDelta(0.5, lruRatio, 0.01)
+}
+
+// TestStats 测试命中和未命中的统计，A1out中的键不计入元素数量
+func (s *TwoQTestSuite) TestStats() {
+	for i := range 5 {
+		s.NoError(s.q.Put(strconv.Itoa(i), i))
+	}
+	s.q.Get("0")
+	s.q.Get("4")
+
+	st := s.q.Stats()
+	s.Equal(uint64(1), st.Hits)
+	s.Equal(uint64(1), st.Misses)
+	s.Equal(uint64(1), st.Evictions[cache.EvictCapacity])
+	s.Equal(4, st.Size)
+}
+
+// TestTwoQ 运行所有2Q测试
+func TestTwoQ(t *testing.T) {
+	suite.Run(t, new(TwoQTestSuite))
+}
+
+// TestConformance 运行缓存接口的一致性测试
+func TestConformance(t *testing.T) {
+	cachetest.Run(t, func(opts ...cache.Option) cache.Cache[string, int] {
+		return New[string, int](opts...)
+	})
+}